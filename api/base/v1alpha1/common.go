@@ -2,7 +2,14 @@ package v1alpha1
 
 import (
 	"context"
-
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -15,6 +22,51 @@ const (
 	ProviderLabel = Group + "/provider"
 )
 
+// kinds of the resources of this group
+const (
+	KindPrompt     = "Prompt"
+	KindLLM        = "LLM"
+	KindWorker     = "Worker"
+	KindModel      = "Model"
+	KindDataSource = "DataSource"
+)
+
+// keys of the endpoint auth secret
+const (
+	AuthSecretAPIKey   = "apiKey"
+	AuthSecretToken    = "token"
+	AuthSecretUsername = "username"
+	AuthSecretPassword = "password"
+	AuthSecretCABundle = "ca.crt"
+	AuthSecretTLSCert  = corev1.TLSCertKey
+	AuthSecretTLSKey   = corev1.TLSPrivateKeyKey
+)
+
+// ErrInvalidAuthSecret is returned when the auth secret lacks the keys its auth type requires
+var ErrInvalidAuthSecret = errors.New("invalid auth secret")
+
+// AuthType tells how the credentials of the endpoint auth secret are sent
+// +kubebuilder:validation:Enum=apiKey;bearer;basic;header;azure;tls
+type AuthType string
+
+const (
+	// AuthTypeAPIKey sends apiKey the way the provider api expects it
+	AuthTypeAPIKey AuthType = "apiKey"
+	// AuthTypeBearer sends token in the Authorization header
+	AuthTypeBearer AuthType = "bearer"
+	// AuthTypeBasic sends username and password with basic auth
+	AuthTypeBasic AuthType = "basic"
+	// AuthTypeHeader sends apiKey in the header named by Endpoint.AuthHeader
+	AuthTypeHeader AuthType = "header"
+	// AuthTypeAzure sends apiKey in the api-key header, like azure openai expects
+	AuthTypeAzure AuthType = "azure"
+	// AuthTypeTLS authenticates with the client certificate only
+	AuthTypeTLS AuthType = "tls"
+)
+
+// azureAPIKeyHeader carries the api key of azure openai
+const azureAPIKeyHeader = "api-key"
+
 type CommonSpec struct {
 	// Creator defines datasource creator (AUTO-FILLED by webhook)
 	Creator string `json:"creator,omitempty"`
@@ -32,11 +84,14 @@ const (
 	ProviderTypeUnknown  ProviderType = "unknown"
 	ProviderType3rdParty ProviderType = "3rdParty"
 	ProviderTypeWorker   ProviderType = "worker"
+	ProviderTypeGroup    ProviderType = "group"
 )
 
 type Provider struct {
 	Endpoint *Endpoint                    `json:"endpoint,omitempty"`
 	Worker   *corev1.TypedObjectReference `json:"worker,omitempty"`
+	// Group routes the calls to other LLMs
+	Group *LLMGroup `json:"group,omitempty"`
 }
 
 func (p Provider) GetType() ProviderType {
@@ -46,6 +101,9 @@ func (p Provider) GetType() ProviderType {
 	if p.Worker != nil {
 		return ProviderTypeWorker
 	}
+	if p.Group != nil {
+		return ProviderTypeGroup
+	}
 	return ProviderTypeUnknown
 }
 
@@ -59,12 +117,32 @@ type Endpoint struct {
 	// +kubebuilder:validation:Required
 	InternalURL string `json:"internalURL,omitempty"`
 
-	// AuthSecret if the chart repository requires auth authentication,
-	// set the username and password to secret, with the field user and password respectively.
+	// AuthSecret holds the credentials of the endpoint, the keys it needs depend on AuthType:
+	// apiKey for apiKey, header and azure, token for bearer, username and password for basic.
+	// A client certificate is presented if tls.crt and tls.key are set, ca.crt is trusted in addition to the system certificates.
 	AuthSecret *corev1.TypedLocalObjectReference `json:"authSecret,omitempty"`
 
+	// AuthType tells how the credentials in AuthSecret are sent, defaults to apiKey
+	// +optional
+	AuthType AuthType `json:"authType,omitempty"`
+
+	// AuthHeader is the name of the header carrying apiKey when AuthType is header
+	// +optional
+	AuthHeader string `json:"authHeader,omitempty"`
+
 	// Insecure if the endpoint needs a secure connection
 	Insecure bool `json:"insecure,omitempty"`
+
+	// Headers sent with every request to the endpoint, such as an organization or project id
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// GetURL returns InternalURL when running inside the cluster, URL otherwise
+func (o Endpoint) GetURL() string {
+	if o.InternalURL != "" && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return o.InternalURL
+	}
+	return o.URL
 }
 
 func (o Endpoint) AuthData(ctx context.Context, ns string, c client.Client) (map[string][]byte, error) {
@@ -84,5 +162,79 @@ func (o Endpoint) AuthAPIKey(ctx context.Context, ns string, c client.Client) (s
 	if err != nil {
 		return "", err
 	}
-	return string(data["apiKey"]), nil
+	return string(data[AuthSecretAPIKey]), nil
+}
+
+// GetAuthType returns the auth type of this endpoint
+func (o Endpoint) GetAuthType() AuthType {
+	return lo.Ternary(o.AuthType == "", AuthTypeAPIKey, o.AuthType)
+}
+
+// ClientOptions returns the options of a llm client connecting to this endpoint,
+// with the credentials of the auth secret applied according to the auth type.
+func (o Endpoint) ClientOptions(ctx context.Context, ns string, c client.Client) (llms.ClientOptions, error) {
+	data, err := o.AuthData(ctx, ns, c)
+	if err != nil {
+		return llms.ClientOptions{}, err
+	}
+	opts := llms.ClientOptions{
+		BaseURL:  o.GetURL(),
+		Insecure: o.Insecure,
+		CABundle: data[AuthSecretCABundle],
+		Headers:  o.Headers,
+	}
+	if lo.IsNil(o.AuthSecret) {
+		return opts, nil
+	}
+
+	authType := o.GetAuthType()
+	authUsage := fmt.Sprintf("%s auth", authType)
+	requireKeys := func(usage string, keys ...string) error {
+		missing := lo.Filter(keys, func(key string, _ int) bool { return len(data[key]) == 0 })
+		if len(missing) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%w %s: %s requires keys %s", ErrInvalidAuthSecret, o.AuthSecret.Name, usage, strings.Join(missing, ", "))
+	}
+	switch authType {
+	case AuthTypeAPIKey:
+		err = requireKeys(authUsage, AuthSecretAPIKey)
+		opts.APIKey = string(data[AuthSecretAPIKey])
+	case AuthTypeBearer:
+		err = requireKeys(authUsage, AuthSecretToken)
+		opts.AuthHeaders = map[string]string{"Authorization": "Bearer " + string(data[AuthSecretToken])}
+	case AuthTypeBasic:
+		err = requireKeys(authUsage, AuthSecretUsername, AuthSecretPassword)
+		credentials := base64.StdEncoding.EncodeToString([]byte(string(data[AuthSecretUsername]) + ":" + string(data[AuthSecretPassword])))
+		opts.AuthHeaders = map[string]string{"Authorization": "Basic " + credentials}
+	case AuthTypeHeader:
+		if o.AuthHeader == "" {
+			return opts, errors.New("authHeader is required for header auth")
+		}
+		err = requireKeys(authUsage, AuthSecretAPIKey)
+		opts.AuthHeaders = map[string]string{o.AuthHeader: string(data[AuthSecretAPIKey])}
+	case AuthTypeAzure:
+		err = requireKeys(authUsage, AuthSecretAPIKey)
+		opts.AuthHeaders = map[string]string{azureAPIKeyHeader: string(data[AuthSecretAPIKey])}
+	case AuthTypeTLS:
+		err = requireKeys(authUsage, AuthSecretTLSCert, AuthSecretTLSKey)
+	default:
+		return opts, fmt.Errorf("unsupported auth type %s", authType)
+	}
+	if err != nil {
+		return opts, err
+	}
+
+	// client certificates can be combined with any auth type
+	if len(data[AuthSecretTLSCert]) > 0 || len(data[AuthSecretTLSKey]) > 0 {
+		if err := requireKeys("client certificate", AuthSecretTLSCert, AuthSecretTLSKey); err != nil {
+			return opts, err
+		}
+		certificate, err := tls.X509KeyPair(data[AuthSecretTLSCert], data[AuthSecretTLSKey])
+		if err != nil {
+			return opts, fmt.Errorf("%w %s: %w", ErrInvalidAuthSecret, o.AuthSecret.Name, err)
+		}
+		opts.Certificates = []tls.Certificate{certificate}
+	}
+	return opts, nil
 }
@@ -0,0 +1,137 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// testKeyPair returns a pem encoded self-signed client certificate and its key
+func testKeyPair(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestEndpointClientOptions(t *testing.T) {
+	cert, key := testKeyPair(t)
+	tests := []struct {
+		name            string
+		endpoint        Endpoint
+		data            map[string][]byte
+		wantAPIKey      string
+		wantAuthHeaders map[string]string
+		wantCertificate bool
+		wantInvalid     bool
+	}{
+		{name: "api key", data: map[string][]byte{AuthSecretAPIKey: []byte("key")}, wantAPIKey: "key"},
+		{name: "missing api key", data: map[string][]byte{AuthSecretToken: []byte("token")}, wantInvalid: true},
+		{
+			name:            "bearer",
+			endpoint:        Endpoint{AuthType: AuthTypeBearer},
+			data:            map[string][]byte{AuthSecretToken: []byte("token")},
+			wantAuthHeaders: map[string]string{"Authorization": "Bearer token"},
+		},
+		{
+			name:            "basic",
+			endpoint:        Endpoint{AuthType: AuthTypeBasic},
+			data:            map[string][]byte{AuthSecretUsername: []byte("user"), AuthSecretPassword: []byte("pass")},
+			wantAuthHeaders: map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+		},
+		{
+			name:            "header",
+			endpoint:        Endpoint{AuthType: AuthTypeHeader, AuthHeader: "X-Api-Key"},
+			data:            map[string][]byte{AuthSecretAPIKey: []byte("key")},
+			wantAuthHeaders: map[string]string{"X-Api-Key": "key"},
+		},
+		{
+			name:            "tls",
+			endpoint:        Endpoint{AuthType: AuthTypeTLS},
+			data:            map[string][]byte{AuthSecretTLSCert: cert, AuthSecretTLSKey: key},
+			wantCertificate: true,
+		},
+		{name: "client certificate without key", data: map[string][]byte{AuthSecretAPIKey: []byte("key"), AuthSecretTLSCert: cert}, wantInvalid: true},
+	}
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"}, Data: tt.data}).Build()
+			endpoint := tt.endpoint
+			endpoint.AuthSecret = &corev1.TypedLocalObjectReference{Name: "auth"}
+			opts, err := endpoint.ClientOptions(context.Background(), "default", c)
+			if errors.Is(err, ErrInvalidAuthSecret) != tt.wantInvalid {
+				t.Fatalf("expected invalid auth secret %v, got %v", tt.wantInvalid, err)
+			}
+			if tt.wantInvalid {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opts.APIKey != tt.wantAPIKey {
+				t.Errorf("expected api key %q, got %q", tt.wantAPIKey, opts.APIKey)
+			}
+			if len(opts.AuthHeaders) > 0 || len(tt.wantAuthHeaders) > 0 {
+				if !reflect.DeepEqual(opts.AuthHeaders, tt.wantAuthHeaders) {
+					t.Errorf("expected auth headers %v, got %v", tt.wantAuthHeaders, opts.AuthHeaders)
+				}
+			}
+			if (len(opts.Certificates) > 0) != tt.wantCertificate {
+				t.Errorf("expected client certificate %v, got %d certificates", tt.wantCertificate, len(opts.Certificates))
+			}
+		})
+	}
+}
@@ -20,6 +20,8 @@ const (
 	// TypeDone resources are believed to be processed
 	TypeDone           ConditionType = "Done"
 	TypeDataProcessing ConditionType = "DataProcessing"
+	// TypeProgressing resources are still receiving output
+	TypeProgressing ConditionType = "Progressing"
 )
 
 // A ConditionReason represents the reason a resource is in a condition.
@@ -28,11 +30,20 @@ type ConditionReason string
 
 // Some common Condition reasons.
 const (
-	ReasonAvailable        ConditionReason = "Available"
-	ReasonUnavailable      ConditionReason = "Unavailable"
-	ReasonReconcileSuccess ConditionReason = "ReconcileSuccess"
-	ReasonReconcileError   ConditionReason = "ReconcileError"
-	ReasonReconcilePaused  ConditionReason = "ReconcilePaused"
+	ReasonAvailable ConditionReason = "Available"
+	// ReasonPartiallyAvailable resources are ready but some of their parts are not
+	ReasonPartiallyAvailable ConditionReason = "PartiallyAvailable"
+	ReasonUnavailable        ConditionReason = "Unavailable"
+	ReasonReconcileSuccess   ConditionReason = "ReconcileSuccess"
+	ReasonReconcileError     ConditionReason = "ReconcileError"
+	ReasonReconcilePaused    ConditionReason = "ReconcilePaused"
+	ReasonStreaming          ConditionReason = "Streaming"
+	ReasonThrottled          ConditionReason = "Throttled"
+	ReasonInvalidAuth        ConditionReason = "InvalidAuth"
+	// ReasonOffline resources are scaled down on purpose
+	ReasonOffline ConditionReason = "Offline"
+	// ReasonPending resources are waiting for their workload to become ready
+	ReasonPending ConditionReason = "Pending"
 
 	ReasonFileSyncing     ConditionReason = "FileSyncing"
 	ReasonFileSyncFailed  ConditionReason = "FileSyncFailed"
@@ -202,7 +213,7 @@ func (s *ConditionedStatus) IsReady() bool {
 
 func (s *ConditionedStatus) IsOffline() bool {
 	readyCond := s.GetCondition(TypeReady)
-	return readyCond.Status == corev1.ConditionFalse && readyCond.Reason == "Offline"
+	return readyCond.Status == corev1.ConditionFalse && readyCond.Reason == ReasonOffline
 }
 
 func (s *ConditionedStatus) WaitingCompleteCondition() []Condition {
@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the webhooks of DataSource with the manager
+func (r *DataSource) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&dataSourceWebhook{}).
+		WithValidator(&dataSourceWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-base-fleezesd-io-v1alpha1-datasource,mutating=true,failurePolicy=fail,sideEffects=None,groups=base.fleezesd.io,resources=datasources,verbs=create;update,versions=v1alpha1,name=mdatasource.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-base-fleezesd-io-v1alpha1-datasource,mutating=false,failurePolicy=fail,sideEffects=None,groups=base.fleezesd.io,resources=datasources,verbs=create;update,versions=v1alpha1,name=vdatasource.kb.io,admissionReviewVersions=v1
+
+// dataSourceWebhook defaults and validates DataSources
+// +kubebuilder:object:generate=false
+type dataSourceWebhook struct{}
+
+var (
+	_ webhook.CustomDefaulter = &dataSourceWebhook{}
+	_ webhook.CustomValidator = &dataSourceWebhook{}
+)
+
+// Default implements webhook.CustomDefaulter
+func (w *dataSourceWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	ds, ok := obj.(*DataSource)
+	if !ok {
+		return fmt.Errorf("expected a DataSource but got %T", obj)
+	}
+	webhooklog.V(1).Info("default", "datasource", ds.Name)
+
+	setCreator(ctx, &ds.Spec.CommonSpec)
+	return nil
+}
+
+// ValidateCreate implements webhook.CustomValidator
+func (w *dataSourceWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return w.validate(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (w *dataSourceWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return w.validate(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (w *dataSourceWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *dataSourceWebhook) validate(obj runtime.Object) (admission.Warnings, error) {
+	ds, ok := obj.(*DataSource)
+	if !ok {
+		return nil, fmt.Errorf("expected a DataSource but got %T", obj)
+	}
+	webhooklog.V(1).Info("validate", "datasource", ds.Name)
+
+	spec := field.NewPath("spec")
+	// only oss and web are reached by url, postgresql and rdma are configured by their own fields
+	errs := ds.Spec.Endpoint.validateSettings(spec.Child("endpoint"))
+	if (ds.Spec.OSS != nil || ds.Spec.Web != nil) && ds.Spec.Endpoint.URL == "" {
+		errs = append(errs, field.Required(spec.Child("endpoint", "url"), "oss and web datasources are reached by url"))
+	}
+	if lo.Count([]bool{ds.Spec.OSS != nil, ds.Spec.RDMA != nil, ds.Spec.PostgreSQL != nil, ds.Spec.Web != nil}, true) > 1 {
+		errs = append(errs, field.Invalid(spec, "", "only one of oss, rdma, postgresql and web may be set"))
+	}
+	return nil, invalid(KindDataSource, ds.Name, errs)
+}
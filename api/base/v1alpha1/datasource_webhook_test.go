@@ -0,0 +1,53 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDataSourceWebhookValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   DataSourceSpec
+		fields []string
+	}{
+		{name: "oss", spec: DataSourceSpec{Endpoint: Endpoint{URL: "http://minio:9000"}, OSS: &OSS{Bucket: "models"}}},
+		{name: "oss without url", spec: DataSourceSpec{OSS: &OSS{Bucket: "models"}}, fields: []string{"spec.endpoint.url"}},
+		{name: "rdma without url", spec: DataSourceSpec{RDMA: &RDMA{Path: "/opt/models/"}}},
+		{
+			name:   "auth type without secret",
+			spec:   DataSourceSpec{Endpoint: Endpoint{AuthType: AuthTypeBasic}, PostgreSQL: &PostgreSQL{}},
+			fields: []string{"spec.endpoint.authSecret"},
+		},
+		{
+			name:   "two backends",
+			spec:   DataSourceSpec{Endpoint: Endpoint{URL: "http://minio:9000"}, OSS: &OSS{}, PostgreSQL: &PostgreSQL{}},
+			fields: []string{"spec"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &DataSource{ObjectMeta: metav1.ObjectMeta{Name: "ds"}, Spec: tt.spec}
+			_, err := (&dataSourceWebhook{}).ValidateCreate(context.Background(), ds)
+			expectInvalid(t, err, tt.fields...)
+		})
+	}
+}
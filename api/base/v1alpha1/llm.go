@@ -2,6 +2,14 @@ package v1alpha1
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fleezesd/llm-operator/pkg/llms"
 	"github.com/samber/lo"
@@ -17,6 +25,18 @@ func (llm LLM) AuthAPIKey(ctx context.Context, c client.Client) (string, error)
 	return llm.Spec.Endpoint.AuthAPIKey(ctx, llm.GetNamespace(), c)
 }
 
+// ClientOptions returns the options of a client connecting to the endpoint of this llm.
+// Worker llms connect to the endpoint filled from their worker.
+func (llm LLM) ClientOptions(ctx context.Context, c client.Client) (llms.ClientOptions, error) {
+	if lo.IsNil(llm.Spec.Endpoint) {
+		if llm.Spec.Provider.GetType() == ProviderTypeWorker && !lo.IsNil(llm.Status.Endpoint) {
+			return llm.Status.Endpoint.ClientOptions(ctx, llm.GetNamespace(), c)
+		}
+		return llms.ClientOptions{}, nil
+	}
+	return llm.Spec.Endpoint.ClientOptions(ctx, llm.GetNamespace(), c)
+}
+
 func (llm LLM) Get3rdPartyModels() []string {
 	if llm.Spec.Provider.GetType() != ProviderType3rdParty {
 		return nil
@@ -26,11 +46,46 @@ func (llm LLM) Get3rdPartyModels() []string {
 		return llm.Spec.Models
 	}
 
-	switch llm.Spec.Type {
-	case llms.OpenAI:
-		return llms.OpenAIModels
+	provider, err := llms.GetProvider(llm.Spec.Type)
+	if err != nil {
+		return []string{}
+	}
+	return provider.DefaultModels
+}
+
+// Filter returns the models matching this filter, a nil filter matches all models
+func (f *ModelFilter) Filter(models []string) []string {
+	if f == nil {
+		return models
+	}
+	include, exclude := globs(f.Include), globs(f.Exclude)
+	return lo.Filter(models, func(model string, _ int) bool {
+		return (len(include) == 0 || matchAny(include, model)) && !matchAny(exclude, model)
+	})
+}
+
+// globs compiles glob patterns, unlike path.Match * also matches / which is common in model names
+func globs(patterns []string) []*regexp.Regexp {
+	return lo.Map(patterns, func(pattern string, _ int) *regexp.Regexp {
+		expr := regexp.QuoteMeta(pattern)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		return regexp.MustCompile("^" + expr + "$")
+	})
+}
+
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	return lo.SomeBy(patterns, func(pattern *regexp.Regexp) bool { return pattern.MatchString(s) })
+}
+
+// IsModelAvailable reports whether model can be called on this llm.
+// All models are considered available until the llm published its models,
+// models which failed their last health check are not.
+func (llm LLM) IsModelAvailable(model string) bool {
+	if lo.Contains(llm.Status.UnavailableModels(), model) {
+		return false
 	}
-	return []string{}
+	return len(llm.Status.Models) == 0 || lo.Contains(llm.Status.Models, model)
 }
 
 // llm condition
@@ -69,3 +124,193 @@ func (llm LLM) ReadyCondition(msg string) Condition {
 		LastSuccessfulTime: metav1.Now(),
 	}
 }
+
+// PartiallyReadyCondition is a ready condition of a llm on which some models are unavailable
+func (llm LLM) PartiallyReadyCondition(msg string) Condition {
+	currCon := llm.Status.GetCondition(TypeReady)
+	if currCon.Status == corev1.ConditionTrue && currCon.Reason == ReasonPartiallyAvailable && currCon.Message == msg {
+		return currCon
+	}
+	return Condition{
+		Type:               TypeReady,
+		Status:             corev1.ConditionTrue,
+		Reason:             ReasonPartiallyAvailable,
+		Message:            msg,
+		LastTransitionTime: metav1.Now(),
+		LastSuccessfulTime: metav1.Now(),
+	}
+}
+
+// UnavailableModels returns the models which failed their last check
+func (status LLMStatus) UnavailableModels() []string {
+	return lo.FilterMap(status.ModelHealth, func(model ModelHealth, _ int) (string, bool) {
+		return model.Name, !model.Available
+	})
+}
+
+// NewTokenUsage converts the usage reported by a llm call
+func NewTokenUsage(usage llms.Usage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+		TotalTokens:      int64(usage.TotalTokens),
+	}
+}
+
+// PricingFor returns the configured price of model, or nil if it has none
+func (spec LLMSpec) PricingFor(model string) *ModelPricing {
+	for i := range spec.Pricing {
+		if spec.Pricing[i].Model == model {
+			return &spec.Pricing[i]
+		}
+	}
+	return nil
+}
+
+// Cost estimates the cost of usage based on this pricing
+func (pricing ModelPricing) Cost(usage TokenUsage) (*big.Rat, error) {
+	promptPrice, ok := new(big.Rat).SetString(pricing.PromptPrice)
+	if !ok {
+		return nil, fmt.Errorf("invalid prompt price %q of model %s", pricing.PromptPrice, pricing.Model)
+	}
+	completionPrice, ok := new(big.Rat).SetString(pricing.CompletionPrice)
+	if !ok {
+		return nil, fmt.Errorf("invalid completion price %q of model %s", pricing.CompletionPrice, pricing.Model)
+	}
+	cost := new(big.Rat).Mul(promptPrice, new(big.Rat).SetInt64(usage.PromptTokens))
+	cost.Add(cost, new(big.Rat).Mul(completionPrice, new(big.Rat).SetInt64(usage.CompletionTokens)))
+	// prices are per one million tokens
+	return cost.Quo(cost, big.NewRat(1000000, 1)), nil
+}
+
+// RecordUsage adds the usage of one call to the cumulative usage of model
+// and refreshes its estimated cost
+func (llm *LLM) RecordUsage(model string, usage TokenUsage) error {
+	idx := -1
+	for i := range llm.Status.Usage {
+		if llm.Status.Usage[i].Model == model {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		llm.Status.Usage = append(llm.Status.Usage, ModelUsage{Model: model})
+		idx = len(llm.Status.Usage) - 1
+	}
+	modelUsage := &llm.Status.Usage[idx]
+	modelUsage.PromptTokens += usage.PromptTokens
+	modelUsage.CompletionTokens += usage.CompletionTokens
+	modelUsage.TotalTokens += usage.TotalTokens
+	modelUsage.Calls++
+	return llm.EstimateCosts()
+}
+
+// EstimateCosts recalculates the estimated cost of the cumulative usage with the current pricing.
+// Cost is always derived from the token totals, so a pricing change reprices the whole history.
+func (llm *LLM) EstimateCosts() error {
+	var errs []error
+	for i := range llm.Status.Usage {
+		modelUsage := &llm.Status.Usage[i]
+		pricing := llm.Spec.PricingFor(modelUsage.Model)
+		if pricing == nil {
+			modelUsage.EstimatedCost, modelUsage.Currency = "", ""
+			continue
+		}
+		cost, err := pricing.Cost(modelUsage.TokenUsage)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		modelUsage.EstimatedCost = cost.FloatString(6)
+		modelUsage.Currency = lo.Ternary(pricing.Currency == "", "USD", pricing.Currency)
+	}
+	return errors.Join(errs...)
+}
+
+// CheckBackendTypes returns an error if the backends of a group have different types.
+// A prompt carries the params of one llm type, so it can not fail over to a backend of another type.
+func CheckBackendTypes(backends []LLM) error {
+	backendTypes := lo.Uniq(lo.Map(backends, func(llm LLM, _ int) string { return string(llm.Spec.Type) }))
+	if len(backendTypes) > 1 {
+		return fmt.Errorf("backends of a llm group must have the same type, found %s", strings.Join(backendTypes, ", "))
+	}
+	return nil
+}
+
+// OrderedBackends returns the backends in the order they are tried
+func (g LLMGroup) OrderedBackends() []LLMBackend {
+	if g.Strategy != GroupStrategyWeighted {
+		return g.Backends
+	}
+	// weighted random sampling without replacement, backends without weight go last
+	remaining := lo.Filter(g.Backends, func(b LLMBackend, _ int) bool { return b.Weight > 0 })
+	ordered := make([]LLMBackend, 0, len(g.Backends))
+	for len(remaining) > 0 {
+		total := lo.SumBy(remaining, func(b LLMBackend) int { return int(b.Weight) })
+		pick := rand.IntN(total)
+		for i, b := range remaining {
+			if pick -= int(b.Weight); pick < 0 {
+				ordered = append(ordered, b)
+				remaining = append(remaining[:i:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+	return append(ordered, lo.Filter(g.Backends, func(b LLMBackend, _ int) bool { return b.Weight <= 0 })...)
+}
+
+// IsReady reports whether the Ready condition of this llm is true
+func (llm LLM) IsReady() bool {
+	return llm.Status.GetCondition(TypeReady).Status == corev1.ConditionTrue
+}
+
+// DefaultProbeInterval is the interval between two probes of a llm without probe interval
+const DefaultProbeInterval = time.Hour
+
+// GetProbeInterval returns the interval between two probes of this llm
+func (llm LLM) GetProbeInterval() time.Duration {
+	if llm.Spec.Probe == nil || llm.Spec.Probe.Interval == nil || llm.Spec.Probe.Interval.Duration <= 0 {
+		return DefaultProbeInterval
+	}
+	return llm.Spec.Probe.Interval.Duration
+}
+
+// GetProbeMode returns the probe mode of this llm, canList tells whether the llm can list its models
+func (llm LLM) GetProbeMode(canList bool) ProbeMode {
+	if llm.Spec.Probe != nil && llm.Spec.Probe.Mode != "" {
+		return llm.Spec.Probe.Mode
+	}
+	return lo.Ternary(canList, ProbeModeListModels, ProbeModeMinimalCompletion)
+}
+
+// GetRetryPolicy returns the retry policy of calls to this llm
+func (llm LLM) GetRetryPolicy() llms.RetryPolicy {
+	policy := llms.DefaultRetryPolicy
+	if llm.Spec.RetryPolicy == nil {
+		return policy
+	}
+	policy.MaxRetries = int(llm.Spec.RetryPolicy.MaxRetries)
+	if backoff := llm.Spec.RetryPolicy.InitialBackoff; backoff != nil {
+		policy.InitialBackoff = backoff.Duration
+	}
+	if backoff := llm.Spec.RetryPolicy.MaxBackoff; backoff != nil {
+		policy.MaxBackoff = backoff.Duration
+	}
+	// an invalid multiplier is rejected by the webhook, the default is kept for it
+	if multiplier, err := strconv.ParseFloat(llm.Spec.RetryPolicy.Multiplier, 64); err == nil && multiplier >= 1 {
+		policy.Multiplier = multiplier
+	}
+	return policy
+}
+
+// GetRateLimits returns the rate limits of calls to this llm
+func (llm LLM) GetRateLimits() llms.RateLimits {
+	if llm.Spec.RateLimits == nil {
+		return llms.RateLimits{}
+	}
+	return llms.RateLimits{
+		RequestsPerMinute: int(llm.Spec.RateLimits.RequestsPerMinute),
+		TokensPerMinute:   int(llm.Spec.RateLimits.TokensPerMinute),
+		MaxConcurrency:    int(llm.Spec.RateLimits.MaxConcurrency),
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samber/lo"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+func TestModelPricingCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		pricing ModelPricing
+		usage   TokenUsage
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "prompt and completion",
+			pricing: ModelPricing{Model: "gpt-4o", PromptPrice: "2.5", CompletionPrice: "10"},
+			usage:   TokenUsage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+			want:    "0.007500",
+		},
+		{
+			name:    "below a millionth",
+			pricing: ModelPricing{Model: "gpt-4o-mini", PromptPrice: "0.15", CompletionPrice: "0.6"},
+			usage:   TokenUsage{PromptTokens: 3, CompletionTokens: 1, TotalTokens: 4},
+			want:    "0.000001",
+		},
+		{name: "invalid price", pricing: ModelPricing{Model: "gpt-4o", PromptPrice: "free", CompletionPrice: "10"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, err := tt.pricing.Cost(tt.usage)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && cost.FloatString(6) != tt.want {
+				t.Errorf("expected cost %s, got %s", tt.want, cost.FloatString(6))
+			}
+		})
+	}
+}
+
+func TestRecordUsage(t *testing.T) {
+	llm := &LLM{Spec: LLMSpec{Pricing: []ModelPricing{{Model: "gpt-4o", PromptPrice: "2.5", CompletionPrice: "10"}}}}
+	usage := TokenUsage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500}
+	for _, model := range []string{"gpt-4o", "gpt-4o", "llama-3"} {
+		if err := llm.RecordUsage(model, usage); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []ModelUsage{
+		{Model: "gpt-4o", TokenUsage: TokenUsage{PromptTokens: 2000, CompletionTokens: 1000, TotalTokens: 3000}, Calls: 2, EstimatedCost: "0.015000", Currency: "USD"},
+		// models without pricing are counted but not priced
+		{Model: "llama-3", TokenUsage: usage, Calls: 1},
+	}
+	if !reflect.DeepEqual(llm.Status.Usage, want) {
+		t.Errorf("expected usage %+v, got %+v", want, llm.Status.Usage)
+	}
+
+	// a pricing change reprices the whole history
+	llm.Spec.Pricing[0].PromptPrice = "5"
+	llm.Spec.Pricing[0].CompletionPrice = "20"
+	if err := llm.EstimateCosts(); err != nil {
+		t.Fatal(err)
+	}
+	if cost := llm.Status.Usage[0].EstimatedCost; cost != "0.030000" {
+		t.Errorf("expected the history to be repriced to 0.030000, got %s", cost)
+	}
+}
+
+func TestModelFilter(t *testing.T) {
+	models := []string{"gpt-4o", "gpt-4o-mini", "gpt-3.5-turbo", "meta/llama-3-8b"}
+	tests := []struct {
+		name   string
+		filter *ModelFilter
+		want   []string
+	}{
+		{name: "no filter", want: models},
+		{name: "include", filter: &ModelFilter{Include: []string{"gpt-4o*"}}, want: []string{"gpt-4o", "gpt-4o-mini"}},
+		{name: "exclude wins", filter: &ModelFilter{Include: []string{"gpt-*"}, Exclude: []string{"*mini"}}, want: []string{"gpt-4o", "gpt-3.5-turbo"}},
+		{name: "star matches slashes and dots are literal", filter: &ModelFilter{Include: []string{"meta*", "gpt-3x5*"}}, want: []string{"meta/llama-3-8b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Filter(models); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOrderedBackends(t *testing.T) {
+	backends := []LLMBackend{{Name: "a", Weight: 1}, {Name: "b", Weight: 3}, {Name: "c"}}
+	names := func(backends []LLMBackend) []string {
+		return lo.Map(backends, func(b LLMBackend, _ int) string { return b.Name })
+	}
+
+	if got := names(LLMGroup{Backends: backends}.OrderedBackends()); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("expected the failover order of the spec, got %v", got)
+	}
+
+	group := LLMGroup{Strategy: GroupStrategyWeighted, Backends: backends}
+	first := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		ordered := names(group.OrderedBackends())
+		if len(ordered) != 3 || ordered[2] != "c" {
+			t.Fatalf("expected every backend once and the ones without weight last, got %v", ordered)
+		}
+		first[ordered[0]]++
+	}
+	// b is tried first about three times as often as a
+	if first["b"] < 600 || first["b"] > 900 {
+		t.Errorf("expected b first in about 750 of 1000 orders, got %d", first["b"])
+	}
+}
+
+func TestCheckBackendTypes(t *testing.T) {
+	backend := func(llmType llms.LLMType) LLM { return LLM{Spec: LLMSpec{Type: llmType}} }
+	if err := CheckBackendTypes([]LLM{backend(llms.OpenAI), backend(llms.OpenAI)}); err != nil {
+		t.Errorf("unexpected error for backends of one type: %v", err)
+	}
+	if err := CheckBackendTypes([]LLM{backend(llms.OpenAI), backend(llms.Deepseek)}); err == nil {
+		t.Error("expected an error for backends of different types")
+	}
+}
+
+func TestIsModelAvailable(t *testing.T) {
+	status := LLMStatus{
+		Models:      []string{"gpt-4o", "gpt-4o-mini"},
+		ModelHealth: []ModelHealth{{Name: "gpt-4o", Available: true}, {Name: "gpt-4o-mini", Available: false}},
+	}
+	tests := []struct {
+		name   string
+		status LLMStatus
+		model  string
+		want   bool
+	}{
+		{name: "healthy model", status: status, model: "gpt-4o", want: true},
+		{name: "unhealthy model", status: status, model: "gpt-4o-mini"},
+		{name: "unknown model", status: status, model: "gpt-3.5-turbo"},
+		{name: "models not published yet", model: "gpt-4o", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (LLM{Status: tt.status}).IsModelAvailable(tt.model); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -26,20 +26,231 @@ import (
 
 // LLMSpec defines the desired state of LLM
 type LLMSpec struct {
-	// Type defines the type of llm
-	Type llms.LLMType `json:"type"`
+	// Type defines the type of llm, it is not needed by groups
+	// +optional
+	Type llms.LLMType `json:"type,omitempty"`
 
 	// Provider defines the provider info which provide this llm service
 	Provider `json:"provider,omitempty"`
 
 	// Models provided by this LLM
-	// If not set,we will use default model list based on LLMType
+	// If not set, the models are discovered from the model listing api of the llm,
+	// or the default model list based on LLMType is used if it has none
 	Models []string `json:"models,omitempty"`
+
+	// ModelFilter narrows down the models of this LLM
+	// +optional
+	ModelFilter *ModelFilter `json:"modelFilter,omitempty"`
+
+	// Pricing is the price table used to estimate the cost of prompt calls
+	// +optional
+	Pricing []ModelPricing `json:"pricing,omitempty"`
+
+	// RetryPolicy of the calls to this llm, a default policy is used if it is not set
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// Probe configures the health checks of the models
+	// +optional
+	Probe *Probe `json:"probe,omitempty"`
+
+	// RateLimits of the calls to this llm, they are enforced by the operator
+	// across all prompts using this llm
+	// +optional
+	RateLimits *RateLimits `json:"rateLimits,omitempty"`
+}
+
+// GroupStrategy is how a llm group picks the backend of a call
+// +kubebuilder:validation:Enum=Ordered;Weighted
+type GroupStrategy string
+
+const (
+	// GroupStrategyOrdered tries the backends in the order they are listed
+	GroupStrategyOrdered GroupStrategy = "Ordered"
+	// GroupStrategyWeighted tries the backends in a random order, where backends with a higher weight come first more often
+	GroupStrategyWeighted GroupStrategy = "Weighted"
+)
+
+// LLMGroup routes calls to the first healthy of its backends,
+// and fails over to the next one when a call fails with a retryable error
+type LLMGroup struct {
+	// Strategy to order the backends with
+	// +kubebuilder:default=Ordered
+	// +optional
+	Strategy GroupStrategy `json:"strategy,omitempty"`
+	// Backends are LLMs in the namespace of the group, they can not be groups themselves
+	// +kubebuilder:validation:MinItems=1
+	Backends []LLMBackend `json:"backends"`
+}
+
+// LLMBackend is a LLM calls of a group can be routed to
+type LLMBackend struct {
+	// Name of the LLM
+	Name string `json:"name"`
+	// Weight of the backend with the Weighted strategy, backends with weight 0 are only tried last
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+	// Model replaces the model of the prompt params on this backend
+	// +optional
+	Model string `json:"model,omitempty"`
+}
+
+// ProbeMode is how the models of a llm are checked
+// +kubebuilder:validation:Enum=ListModels;MinimalCompletion;CustomPrompt
+type ProbeMode string
+
+const (
+	// ProbeModeListModels checks the models are listed by the model listing api, it is free of charge
+	ProbeModeListModels ProbeMode = "ListModels"
+	// ProbeModeMinimalCompletion sends a completion limited to one token to each model
+	ProbeModeMinimalCompletion ProbeMode = "MinimalCompletion"
+	// ProbeModeCustomPrompt sends Probe.Prompt to each model and checks the response
+	ProbeModeCustomPrompt ProbeMode = "CustomPrompt"
+)
+
+// Probe configures the health checks of the models of a llm
+type Probe struct {
+	// Mode of the probe. If not set, ListModels is used if the llm can list its models
+	// and MinimalCompletion otherwise
+	// +optional
+	Mode ProbeMode `json:"mode,omitempty"`
+	// Prompt sent to each model in CustomPrompt mode
+	// +optional
+	Prompt string `json:"prompt,omitempty"`
+	// ExpectedResponse is a regular expression the response to Prompt must match in CustomPrompt mode
+	// +optional
+	ExpectedResponse string `json:"expectedResponse,omitempty"`
+	// Interval between two probes
+	// +kubebuilder:default="1h"
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ModelFilter selects models by name with glob patterns, where * matches any characters and ? a single one
+type ModelFilter struct {
+	// Include only the models matching one of these patterns, all models are included if it is empty
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Exclude the models matching one of these patterns
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// RateLimits of the calls to a llm, an unset or zero limit is not enforced
+type RateLimits struct {
+	// RequestsPerMinute allowed to the llm
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RequestsPerMinute int32 `json:"requestsPerMinute,omitempty"`
+	// TokensPerMinute allowed to the llm, counted from the usage reported by the llm
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TokensPerMinute int32 `json:"tokensPerMinute,omitempty"`
+	// MaxConcurrency is the number of calls to the llm in flight at the same time,
+	// the manager calls at most --prompt-concurrency prompts at once
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrency int32 `json:"maxConcurrency,omitempty"`
+}
+
+// RetryPolicy retries throttled(429), timed out and failed(5xx) calls with exponential backoff.
+// A Retry-After header of the api replaces the backoff.
+type RetryPolicy struct {
+	// MaxRetries after the first attempt, 0 disables retries
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	MaxRetries int32 `json:"maxRetries"`
+	// InitialBackoff is the wait time before the first retry, it grows by Multiplier with each retry
+	// +kubebuilder:default="1s"
+	// +optional
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+	// MaxBackoff caps the wait time between two attempts
+	// +kubebuilder:default="30s"
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+	// Multiplier of the wait time after each retry, as a decimal of at least 1
+	// +kubebuilder:default="2"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	Multiplier string `json:"multiplier,omitempty"`
+}
+
+// ModelPricing defines the price of a model per one million tokens
+type ModelPricing struct {
+	// Model the price applies to
+	Model string `json:"model"`
+	// PromptPrice is the price of one million prompt tokens, as a decimal
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	PromptPrice string `json:"promptPrice"`
+	// CompletionPrice is the price of one million completion tokens, as a decimal
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	CompletionPrice string `json:"completionPrice"`
+	// Currency of the prices
+	// +kubebuilder:default=USD
+	// +optional
+	Currency string `json:"currency,omitempty"`
+}
+
+// TokenUsage is the number of tokens consumed by llm calls
+type TokenUsage struct {
+	PromptTokens     int64 `json:"promptTokens"`
+	CompletionTokens int64 `json:"completionTokens"`
+	TotalTokens      int64 `json:"totalTokens"`
+}
+
+// ModelUsage is the cumulative usage and estimated cost of a model
+type ModelUsage struct {
+	// Model the usage was recorded for
+	Model      string `json:"model"`
+	TokenUsage `json:",inline"`
+	// Calls is the number of recorded prompt calls
+	Calls int64 `json:"calls"`
+	// EstimatedCost of the recorded tokens based on spec.pricing
+	// +optional
+	EstimatedCost string `json:"estimatedCost,omitempty"`
+	// Currency of the estimated cost
+	// +optional
+	Currency string `json:"currency,omitempty"`
+}
+
+// ModelHealth is the health of a model of the llm
+type ModelHealth struct {
+	// Name of the model
+	Name string `json:"name"`
+	// Available is true if the last check of the model succeeded
+	Available bool `json:"available"`
+	// LastCheckTime is the time the model was last checked
+	// +optional
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+	// Latency of the last successful check call
+	// +optional
+	Latency *metav1.Duration `json:"latency,omitempty"`
+	// LastError of the last failed check
+	// +optional
+	LastError string `json:"lastError,omitempty"`
 }
 
 // LLMStatus defines the observed state of LLM
 type LLMStatus struct {
 	ConditionedStatus `json:",inline"`
+
+	// Models available on this llm, prompts calling other models are rejected
+	// +optional
+	Models []string `json:"models,omitempty"`
+
+	// ModelHealth is the health of each model found by the last check
+	// +optional
+	ModelHealth []ModelHealth `json:"modelHealth,omitempty"`
+
+	// Usage is the cumulative token usage of prompts per model
+	// +optional
+	Usage []ModelUsage `json:"usage,omitempty"`
+
+	// Endpoint the calls to a worker llm are sent to, filled from the service of the worker
+	// +optional
+	Endpoint *Endpoint `json:"endpoint,omitempty"`
 }
 
 //+kubebuilder:object:root=true
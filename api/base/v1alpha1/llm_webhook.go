@@ -0,0 +1,207 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+// SetupWebhookWithManager registers the webhooks of LLM with the manager
+func (r *LLM) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&llmWebhook{}).
+		WithValidator(&llmWebhook{reader: mgr.GetAPIReader()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-base-fleezesd-io-v1alpha1-llm,mutating=true,failurePolicy=fail,sideEffects=None,groups=base.fleezesd.io,resources=llms,verbs=create;update,versions=v1alpha1,name=mllm.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-base-fleezesd-io-v1alpha1-llm,mutating=false,failurePolicy=fail,sideEffects=None,groups=base.fleezesd.io,resources=llms,verbs=create;update,versions=v1alpha1,name=vllm.kb.io,admissionReviewVersions=v1
+
+// llmWebhook defaults and validates LLMs
+// +kubebuilder:object:generate=false
+type llmWebhook struct {
+	// reader gets the backends of llm groups, they are not checked if it is nil
+	reader client.Reader
+}
+
+var (
+	_ webhook.CustomDefaulter = &llmWebhook{}
+	_ webhook.CustomValidator = &llmWebhook{}
+)
+
+// Default implements webhook.CustomDefaulter
+func (w *llmWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	llm, ok := obj.(*LLM)
+	if !ok {
+		return fmt.Errorf("expected a LLM but got %T", obj)
+	}
+	webhooklog.V(1).Info("default", "llm", llm.Name)
+
+	// workers serve an openai compatible api
+	if llm.Spec.Type == "" && llm.Spec.Provider.GetType() == ProviderTypeWorker {
+		llm.Spec.Type = llms.OpenAI
+	}
+	if !lo.IsNil(llm.Spec.Endpoint) && llm.Spec.Endpoint.AuthType == "" && !lo.IsNil(llm.Spec.Endpoint.AuthSecret) {
+		llm.Spec.Endpoint.AuthType = AuthTypeAPIKey
+	}
+	return nil
+}
+
+// ValidateCreate implements webhook.CustomValidator
+func (w *llmWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return w.validate(ctx, obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (w *llmWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return w.validate(ctx, newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (w *llmWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *llmWebhook) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	llm, ok := obj.(*LLM)
+	if !ok {
+		return nil, fmt.Errorf("expected a LLM but got %T", obj)
+	}
+	webhooklog.V(1).Info("validate", "llm", llm.Name)
+
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+	provider := spec.Child("provider")
+	switch lo.Count([]bool{llm.Spec.Endpoint != nil, llm.Spec.Worker != nil, llm.Spec.Group != nil}, true) {
+	case 0:
+		errs = append(errs, field.Required(provider, "one of endpoint, worker and group must be set"))
+	case 1:
+	default:
+		errs = append(errs, field.Invalid(provider, "", "only one of endpoint, worker and group may be set"))
+	}
+
+	if llm.Spec.Group == nil {
+		if llm.Spec.Type == "" {
+			errs = append(errs, field.Required(spec.Child("type"), "type is required unless the llm is a group"))
+		} else if _, err := llms.GetProvider(llm.Spec.Type); err != nil {
+			errs = append(errs, field.NotSupported(spec.Child("type"), llm.Spec.Type,
+				lo.Map(llms.RegisteredTypes(), func(t llms.LLMType, _ int) string { return string(t) })))
+		}
+	}
+	if llm.Spec.Endpoint != nil {
+		errs = append(errs, llm.Spec.Endpoint.validate(provider.Child("endpoint"))...)
+	}
+	if llm.Spec.Worker != nil && llm.Spec.Worker.Name == "" {
+		errs = append(errs, field.Required(provider.Child("worker", "name"), ""))
+	}
+	if llm.Spec.Group != nil {
+		backends := provider.Child("group", "backends")
+		seen := map[string]bool{}
+		for i, backend := range llm.Spec.Group.Backends {
+			switch {
+			case backend.Name == llm.Name:
+				errs = append(errs, field.Invalid(backends.Index(i).Child("name"), backend.Name, "a group can not be its own backend"))
+			case seen[backend.Name]:
+				errs = append(errs, field.Duplicate(backends.Index(i).Child("name"), backend.Name))
+			}
+			seen[backend.Name] = true
+		}
+		if err := w.validateBackendTypes(ctx, llm); err != nil {
+			errs = append(errs, field.Invalid(backends, "", err.Error()))
+		}
+	}
+	if policy := llm.Spec.RetryPolicy; policy != nil && policy.Multiplier != "" {
+		path := spec.Child("retryPolicy", "multiplier")
+		if multiplier, err := strconv.ParseFloat(policy.Multiplier, 64); err != nil || multiplier < 1 {
+			errs = append(errs, field.Invalid(path, policy.Multiplier, "must be a decimal of at least 1"))
+		}
+	}
+	if probe := llm.Spec.Probe; probe != nil {
+		if probe.Mode == ProbeModeCustomPrompt && probe.Prompt == "" {
+			errs = append(errs, field.Required(spec.Child("probe", "prompt"), "prompt is required in CustomPrompt mode"))
+		}
+		if _, err := regexp.Compile(probe.ExpectedResponse); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("probe", "expectedResponse"), probe.ExpectedResponse, err.Error()))
+		}
+	}
+	return nil, invalid(KindLLM, llm.Name, errs)
+}
+
+// validateBackendTypes checks that the existing backends of group have the same type,
+// the params of a prompt only fit one type
+func (w *llmWebhook) validateBackendTypes(ctx context.Context, group *LLM) error {
+	if w.reader == nil {
+		return nil
+	}
+	var backends []LLM
+	for _, backend := range group.Spec.Group.Backends {
+		llm := &LLM{}
+		if err := w.reader.Get(ctx, types.NamespacedName{Namespace: group.Namespace, Name: backend.Name}, llm); err != nil {
+			// missing backends are reported by the llm controller
+			continue
+		}
+		backends = append(backends, *llm)
+	}
+	return CheckBackendTypes(backends)
+}
+
+// validate checks the url and the auth settings of the endpoint, the url is required
+func (o Endpoint) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if o.URL == "" {
+		errs = append(errs, field.Required(path.Child("url"), ""))
+	}
+	return append(errs, o.validateSettings(path)...)
+}
+
+// validateSettings checks the urls which are set and the auth settings of the endpoint
+func (o Endpoint) validateSettings(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if o.URL != "" {
+		if _, err := url.Parse(o.URL); err != nil {
+			errs = append(errs, field.Invalid(path.Child("url"), o.URL, err.Error()))
+		}
+	}
+	if o.InternalURL != "" {
+		if _, err := url.Parse(o.InternalURL); err != nil {
+			errs = append(errs, field.Invalid(path.Child("internalURL"), o.InternalURL, err.Error()))
+		}
+	}
+	if o.AuthType != "" && o.AuthSecret == nil {
+		errs = append(errs, field.Required(path.Child("authSecret"), fmt.Sprintf("auth type %s needs an auth secret", o.AuthType)))
+	}
+	if o.AuthType == AuthTypeHeader && o.AuthHeader == "" {
+		errs = append(errs, field.Required(path.Child("authHeader"), "auth type header needs the name of the header"))
+	}
+	return errs
+}
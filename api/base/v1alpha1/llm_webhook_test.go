@@ -0,0 +1,141 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+func TestLLMWebhookDefault(t *testing.T) {
+	secret := &corev1.TypedLocalObjectReference{Name: "auth"}
+	tests := []struct {
+		name         string
+		spec         LLMSpec
+		wantType     llms.LLMType
+		wantAuthType AuthType
+	}{
+		{name: "worker", spec: LLMSpec{Provider: Provider{Worker: &corev1.TypedObjectReference{Name: "worker"}}}, wantType: llms.OpenAI},
+		{
+			name:         "auth secret",
+			spec:         LLMSpec{Type: llms.OpenAI, Provider: Provider{Endpoint: &Endpoint{URL: "https://api.openai.com/v1", AuthSecret: secret}}},
+			wantType:     llms.OpenAI,
+			wantAuthType: AuthTypeAPIKey,
+		},
+		{
+			name: "auth type is kept",
+			spec: LLMSpec{Type: llms.OpenAI, Provider: Provider{
+				Endpoint: &Endpoint{URL: "https://api.openai.com/v1", AuthSecret: secret, AuthType: AuthTypeBearer}}},
+			wantType:     llms.OpenAI,
+			wantAuthType: AuthTypeBearer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &LLM{Spec: tt.spec}
+			if err := (&llmWebhook{}).Default(context.Background(), llm); err != nil {
+				t.Fatal(err)
+			}
+			if llm.Spec.Type != tt.wantType {
+				t.Errorf("expected type %q, got %q", tt.wantType, llm.Spec.Type)
+			}
+			if llm.Spec.Endpoint != nil && llm.Spec.Endpoint.AuthType != tt.wantAuthType {
+				t.Errorf("expected auth type %q, got %q", tt.wantAuthType, llm.Spec.Endpoint.AuthType)
+			}
+		})
+	}
+}
+
+func TestLLMWebhookValidate(t *testing.T) {
+	endpoint := &Endpoint{URL: "https://api.openai.com/v1"}
+	tests := []struct {
+		name   string
+		spec   LLMSpec
+		fields []string
+	}{
+		{name: "endpoint", spec: LLMSpec{Type: llms.OpenAI, Provider: Provider{Endpoint: endpoint}}},
+		{name: "no provider", spec: LLMSpec{Type: llms.OpenAI}, fields: []string{"spec.provider"}},
+		{name: "unknown type", spec: LLMSpec{Type: "unknown", Provider: Provider{Endpoint: endpoint}}, fields: []string{"spec.type"}},
+		{
+			name: "header auth without header",
+			spec: LLMSpec{Type: llms.OpenAI, Provider: Provider{Endpoint: &Endpoint{URL: "https://api.openai.com/v1",
+				AuthType: AuthTypeHeader, AuthSecret: &corev1.TypedLocalObjectReference{Name: "auth"}}}},
+			fields: []string{"spec.provider.endpoint.authHeader"},
+		},
+		{
+			name:   "group backend is the group",
+			spec:   LLMSpec{Provider: Provider{Group: &LLMGroup{Backends: []LLMBackend{{Name: "llm"}}}}},
+			fields: []string{"spec.provider.group.backends[0].name"},
+		},
+		{
+			name:   "retry multiplier below 1",
+			spec:   LLMSpec{Type: llms.OpenAI, Provider: Provider{Endpoint: endpoint}, RetryPolicy: &RetryPolicy{Multiplier: "0.5"}},
+			fields: []string{"spec.retryPolicy.multiplier"},
+		},
+		{
+			name:   "custom prompt probe without prompt",
+			spec:   LLMSpec{Type: llms.OpenAI, Provider: Provider{Endpoint: endpoint}, Probe: &Probe{Mode: ProbeModeCustomPrompt}},
+			fields: []string{"spec.probe.prompt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &LLM{ObjectMeta: metav1.ObjectMeta{Name: "llm"}, Spec: tt.spec}
+			_, err := (&llmWebhook{}).ValidateCreate(context.Background(), llm)
+			expectInvalid(t, err, tt.fields...)
+		})
+	}
+}
+
+func TestLLMWebhookValidateBackendTypes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	backend := func(name string, llmType llms.LLMType) *LLM {
+		return &LLM{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Spec: LLMSpec{Type: llmType}}
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		backend("openai-a", llms.OpenAI), backend("openai-b", llms.OpenAI), backend("deepseek", llms.Deepseek)).Build()
+	tests := []struct {
+		name     string
+		backends []string
+		fields   []string
+	}{
+		{name: "same type", backends: []string{"openai-a", "openai-b"}},
+		{name: "missing backend", backends: []string{"openai-a", "missing"}},
+		{name: "mixed types", backends: []string{"openai-a", "deepseek"}, fields: []string{"spec.provider.group.backends"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &LLMGroup{}
+			for _, name := range tt.backends {
+				group.Backends = append(group.Backends, LLMBackend{Name: name})
+			}
+			llm := &LLM{ObjectMeta: metav1.ObjectMeta{Name: "llm", Namespace: "default"}, Spec: LLMSpec{Provider: Provider{Group: group}}}
+			_, err := (&llmWebhook{reader: reader}).ValidateCreate(context.Background(), llm)
+			expectInvalid(t, err, tt.fields...)
+		})
+	}
+}
@@ -16,13 +16,32 @@ limitations under the License.
 
 package v1alpha1
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// types of models listed in ModelSpec.Types
+const (
+	ModelTypeLLM       = "llm"
+	ModelTypeEmbedding = "embedding"
+)
 
 func (m Model) IsLLMModel() bool {
 	for _, t := range strings.Split(m.Spec.Types, ",") {
-		if strings.ToLower(t) == "llm" {
+		if strings.ToLower(t) == ModelTypeLLM {
 			return true
 		}
 	}
 	return false
 }
+
+// SourceKey returns the namespaced name of the datasource holding the model files
+func (m Model) SourceKey() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: lo.FromPtrOr(m.Spec.Source.Namespace, m.Namespace),
+		Name:      m.Spec.Source.Name,
+	}
+}
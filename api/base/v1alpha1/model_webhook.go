@@ -0,0 +1,110 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the webhooks of Model with the manager
+func (r *Model) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&modelWebhook{}).
+		WithValidator(&modelWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-base-fleezesd-io-v1alpha1-model,mutating=true,failurePolicy=fail,sideEffects=None,groups=base.fleezesd.io,resources=models,verbs=create;update,versions=v1alpha1,name=mmodel.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-base-fleezesd-io-v1alpha1-model,mutating=false,failurePolicy=fail,sideEffects=None,groups=base.fleezesd.io,resources=models,verbs=create;update,versions=v1alpha1,name=vmodel.kb.io,admissionReviewVersions=v1
+
+// modelWebhook defaults and validates Models
+// +kubebuilder:object:generate=false
+type modelWebhook struct{}
+
+var (
+	_ webhook.CustomDefaulter = &modelWebhook{}
+	_ webhook.CustomValidator = &modelWebhook{}
+)
+
+// Default implements webhook.CustomDefaulter
+func (w *modelWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	model, ok := obj.(*Model)
+	if !ok {
+		return fmt.Errorf("expected a Model but got %T", obj)
+	}
+	webhooklog.V(1).Info("default", "model", model.Name)
+
+	setCreator(ctx, &model.Spec.CommonSpec)
+	// normalize the types so that they can be compared as they are
+	model.Spec.Types = strings.Join(lo.Uniq(modelTypes(model.Spec.Types)), ",")
+	return nil
+}
+
+// modelTypes returns the lowercased types listed in types, empty entries are dropped
+func modelTypes(types string) []string {
+	return lo.Compact(lo.Map(strings.Split(types, ","), func(t string, _ int) string {
+		return strings.ToLower(strings.TrimSpace(t))
+	}))
+}
+
+// ValidateCreate implements webhook.CustomValidator
+func (w *modelWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return w.validate(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (w *modelWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return w.validate(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (w *modelWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *modelWebhook) validate(obj runtime.Object) (admission.Warnings, error) {
+	model, ok := obj.(*Model)
+	if !ok {
+		return nil, fmt.Errorf("expected a Model but got %T", obj)
+	}
+	webhooklog.V(1).Info("validate", "model", model.Name)
+
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+	for _, t := range modelTypes(model.Spec.Types) {
+		if t != ModelTypeLLM && t != ModelTypeEmbedding {
+			errs = append(errs, field.NotSupported(spec.Child("types"), t, []string{ModelTypeLLM, ModelTypeEmbedding}))
+		}
+	}
+	if lo.Count([]bool{model.Spec.Source != nil, model.Spec.HuggingFaceRepo != "", model.Spec.ModelScopeRepo != ""}, true) > 1 {
+		errs = append(errs, field.Invalid(spec, "", "only one of source, huggingFaceRepo and modelScopeRepo may be set"))
+	}
+	if model.Spec.ModelScopeRepo != "" && model.Spec.Revision == "" {
+		errs = append(errs, field.Required(spec.Child("revision"), "revision is required to download from modelscope"))
+	}
+	return nil, invalid(KindModel, model.Name, errs)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestModelWebhookDefault(t *testing.T) {
+	model := &Model{Spec: ModelSpec{Types: " LLM , embedding,llm,"}}
+	if err := (&modelWebhook{}).Default(context.Background(), model); err != nil {
+		t.Fatal(err)
+	}
+	if model.Spec.Types != "llm,embedding" {
+		t.Errorf("expected types %q, got %q", "llm,embedding", model.Spec.Types)
+	}
+}
+
+func TestModelWebhookValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   ModelSpec
+		fields []string
+	}{
+		{name: "types", spec: ModelSpec{Types: "llm,Embedding,"}},
+		{name: "unknown type", spec: ModelSpec{Types: "llm,chat"}, fields: []string{"spec.types"}},
+		{
+			name:   "two sources",
+			spec:   ModelSpec{HuggingFaceRepo: "Qwen/Qwen2.5-7B-Instruct", Source: &corev1.TypedObjectReference{Name: "oss"}},
+			fields: []string{"spec"},
+		},
+		{name: "modelscope without revision", spec: ModelSpec{ModelScopeRepo: "qwen/Qwen2.5-7B-Instruct"}, fields: []string{"spec.revision"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &Model{ObjectMeta: metav1.ObjectMeta{Name: "model"}, Spec: tt.spec}
+			_, err := (&modelWebhook{}).ValidateCreate(context.Background(), model)
+			expectInvalid(t, err, tt.fields...)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+// RawModelParams returns the encoded call params of this prompt for llmType
+func (spec PromptSpec) RawModelParams(llmType llms.LLMType) []byte {
+	switch {
+	case llmType == llms.OpenAI && spec.OpenAIParams != nil:
+		return spec.OpenAIParams.Marshal()
+	case llmType == llms.Deepseek && spec.DeepseekParams != nil:
+		return spec.DeepseekParams.Marshal()
+	case spec.Params != nil:
+		return spec.Params.Raw
+	}
+	return nil
+}
+
+// LLMTools returns the tools of this prompt
+func (spec PromptSpec) LLMTools() []llms.Tool {
+	tools := make([]llms.Tool, 0, len(spec.Tools))
+	for _, tool := range spec.Tools {
+		llmTool := llms.Tool{Name: tool.Name, Description: tool.Description}
+		if tool.Parameters != nil {
+			llmTool.Parameters = tool.Parameters.Raw
+		}
+		tools = append(tools, llmTool)
+	}
+	return tools
+}
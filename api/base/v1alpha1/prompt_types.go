@@ -19,7 +19,10 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	llmdeepseek "github.com/fleezesd/llm-operator/pkg/llms/models/deepseek"
 	llmopenai "github.com/fleezesd/llm-operator/pkg/llms/models/openai"
 )
 
@@ -32,6 +35,72 @@ type PromptSpec struct {
 	LLM *corev1.TypedObjectReference `json:"llm"`
 	// OpenAI Prompt Params
 	OpenAIParams *llmopenai.ModelParams `json:"openAIParams,omitempty"`
+	// Deepseek Prompt Params
+	DeepseekParams *llmdeepseek.ModelParams `json:"deepseekParams,omitempty"`
+	// Params for llm types which have no dedicated params field above.
+	// It is decoded by the provider registered for the llm type.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Params *runtime.RawExtension `json:"params,omitempty"`
+	// Tools which the llm can ask to call
+	// +optional
+	Tools []Tool `json:"tools,omitempty"`
+	// ResponseSchema is the json schema the output of the llm must match.
+	// It is sent to providers which support structured output,
+	// and the output is always validated against it. It replaces a response_schema set in the params.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +optional
+	ResponseSchema *runtime.RawExtension `json:"responseSchema,omitempty"`
+	// Template renders messages ahead of the messages in the params
+	// +optional
+	Template *PromptTemplateRef `json:"template,omitempty"`
+}
+
+// PromptTemplateRef refers to a PromptTemplate and the variables to render it with
+type PromptTemplateRef struct {
+	// Name of the PromptTemplate in the namespace of the prompt
+	Name string `json:"name"`
+	// Variables used to render the template
+	// +optional
+	Variables []TemplateVariable `json:"variables,omitempty"`
+}
+
+// TemplateVariable is a variable of a template, with its value set inline or read from a key
+type TemplateVariable struct {
+	// Name of the variable
+	Name string `json:"name"`
+	// Value of the variable
+	// +optional
+	Value string `json:"value,omitempty"`
+	// ValueFrom reads the value from a ConfigMap or Secret key in the namespace of the prompt
+	// +optional
+	ValueFrom *TemplateVariableSource `json:"valueFrom,omitempty"`
+}
+
+// TemplateVariableSource selects the source of a variable value.
+// Exactly one of its fields may be set.
+type TemplateVariableSource struct {
+	// Selects a key of a ConfigMap
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Selects a key of a Secret
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// Tool is a function which the llm can ask to call
+type Tool struct {
+	// Name of the function
+	Name string `json:"name"`
+	// Description tells the llm what the function does and when to call it
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Parameters is the json schema of the function arguments
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +optional
+	Parameters *runtime.RawExtension `json:"parameters,omitempty"`
 }
 
 // PromptStatus defines the observed state of Prompt
@@ -39,10 +108,35 @@ type PromptStatus struct {
 	ConditionedStatus `json:",inline"`
 	// Data retrieved after LLM Call
 	Data []byte `json:"data"`
+	// Partial output received so far while an incremental call is streaming
+	Partial string `json:"partial,omitempty"`
+	// Model which served the last call
+	Model string `json:"model,omitempty"`
+	// Backend is the LLM which served the last call when spec.llm is a group
+	// +optional
+	Backend string `json:"backend,omitempty"`
+	// Usage is the token usage of the last call
+	Usage *TokenUsage `json:"usage,omitempty"`
+	// ToolCalls requested by the llm in the last call
+	ToolCalls []llms.ToolCall `json:"toolCalls,omitempty"`
+	// Result is the output parsed as json once it matched spec.responseSchema
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +optional
+	Result *runtime.RawExtension `json:"result,omitempty"`
+	// RenderedHash is the sha256 of the messages rendered from spec.template
+	// +optional
+	RenderedHash string `json:"renderedHash,omitempty"`
+	// Retries made by the last call before it succeeded or failed
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="llm",type=string,JSONPath=`.spec.llm.name`
+//+kubebuilder:printcolumn:name="done",type=string,JSONPath=`.status.conditions[?(@.type=="Done")].status`
+//+kubebuilder:printcolumn:name="progress",type=string,JSONPath=`.status.conditions[?(@.type=="Progressing")].message`
 
 // Prompt is the Schema for the prompts API
 type Prompt struct {
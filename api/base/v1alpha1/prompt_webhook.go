@@ -0,0 +1,139 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	llmdeepseek "github.com/fleezesd/llm-operator/pkg/llms/models/deepseek"
+	llmopenai "github.com/fleezesd/llm-operator/pkg/llms/models/openai"
+)
+
+// SetupWebhookWithManager registers the webhooks of Prompt with the manager
+func (r *Prompt) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&promptWebhook{}).
+		WithValidator(&promptWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-base-fleezesd-io-v1alpha1-prompt,mutating=true,failurePolicy=fail,sideEffects=None,groups=base.fleezesd.io,resources=prompts,verbs=create;update,versions=v1alpha1,name=mprompt.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-base-fleezesd-io-v1alpha1-prompt,mutating=false,failurePolicy=fail,sideEffects=None,groups=base.fleezesd.io,resources=prompts,verbs=create;update,versions=v1alpha1,name=vprompt.kb.io,admissionReviewVersions=v1
+
+// promptWebhook defaults and validates Prompts
+// +kubebuilder:object:generate=false
+type promptWebhook struct{}
+
+var (
+	_ webhook.CustomDefaulter = &promptWebhook{}
+	_ webhook.CustomValidator = &promptWebhook{}
+)
+
+// Default implements webhook.CustomDefaulter.
+// The fields left empty in the params are filled from the default params of the llm type.
+func (w *promptWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	prompt, ok := obj.(*Prompt)
+	if !ok {
+		return fmt.Errorf("expected a Prompt but got %T", obj)
+	}
+	webhooklog.V(1).Info("default", "prompt", prompt.Name)
+
+	if prompt.Spec.OpenAIParams != nil {
+		params := llmopenai.DefaultModelParams()
+		if err := params.Unmarshal(prompt.Spec.OpenAIParams.Marshal()); err != nil {
+			return err
+		}
+		prompt.Spec.OpenAIParams = &params
+	}
+	if prompt.Spec.DeepseekParams != nil {
+		params := llmdeepseek.DefaultModelParams()
+		if err := params.Unmarshal(prompt.Spec.DeepseekParams.Marshal()); err != nil {
+			return err
+		}
+		prompt.Spec.DeepseekParams = &params
+	}
+	return nil
+}
+
+// ValidateCreate implements webhook.CustomValidator
+func (w *promptWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return w.validate(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (w *promptWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return w.validate(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (w *promptWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *promptWebhook) validate(obj runtime.Object) (admission.Warnings, error) {
+	prompt, ok := obj.(*Prompt)
+	if !ok {
+		return nil, fmt.Errorf("expected a Prompt but got %T", obj)
+	}
+	webhooklog.V(1).Info("validate", "prompt", prompt.Name)
+
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+	if prompt.Spec.LLM == nil {
+		errs = append(errs, field.Required(spec.Child("llm"), "the llm to call is required"))
+	} else if prompt.Spec.LLM.Name == "" {
+		errs = append(errs, field.Required(spec.Child("llm", "name"), ""))
+	}
+
+	tools := spec.Child("tools")
+	seen := map[string]bool{}
+	for i, tool := range prompt.Spec.Tools {
+		switch {
+		case tool.Name == "":
+			errs = append(errs, field.Required(tools.Index(i).Child("name"), ""))
+		case seen[tool.Name]:
+			errs = append(errs, field.Duplicate(tools.Index(i).Child("name"), tool.Name))
+		}
+		seen[tool.Name] = true
+	}
+
+	if prompt.Spec.Template != nil {
+		variables := spec.Child("template", "variables")
+		for i, variable := range prompt.Spec.Template.Variables {
+			path := variables.Index(i)
+			if variable.ValueFrom == nil {
+				continue
+			}
+			if variable.Value != "" {
+				errs = append(errs, field.Invalid(path.Child("valueFrom"), "", "value and valueFrom can not both be set"))
+			}
+			if lo.Count([]bool{variable.ValueFrom.ConfigMapKeyRef != nil, variable.ValueFrom.SecretKeyRef != nil}, true) != 1 {
+				errs = append(errs, field.Invalid(path.Child("valueFrom"), "", "exactly one of configMapKeyRef and secretKeyRef must be set"))
+			}
+		}
+	}
+	return nil, invalid(KindPrompt, prompt.Name, errs)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	llmopenai "github.com/fleezesd/llm-operator/pkg/llms/models/openai"
+)
+
+func TestPromptWebhookDefault(t *testing.T) {
+	prompt := &Prompt{Spec: PromptSpec{OpenAIParams: &llmopenai.ModelParams{Model: "gpt-4o", Temperature: 0.2}}}
+	if err := (&promptWebhook{}).Default(context.Background(), prompt); err != nil {
+		t.Fatal(err)
+	}
+	if got := prompt.Spec.OpenAIParams; got.Model != "gpt-4o" || got.Temperature != 0.2 || got.TopP != llmopenai.DefaultModelParams().TopP {
+		t.Errorf("expected the set openai params to be kept and the others defaulted, got %+v", got)
+	}
+	if prompt.Spec.DeepseekParams != nil {
+		t.Errorf("expected unset params to stay unset, got %+v", prompt.Spec.DeepseekParams)
+	}
+}
+
+func TestPromptWebhookValidate(t *testing.T) {
+	llm := &corev1.TypedObjectReference{Name: "llm"}
+	secretRef := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "vars"}, Key: "topic"}
+	tests := []struct {
+		name   string
+		spec   PromptSpec
+		fields []string
+	}{
+		{name: "llm", spec: PromptSpec{LLM: llm}},
+		{name: "no llm", spec: PromptSpec{}, fields: []string{"spec.llm"}},
+		{name: "duplicate tool", spec: PromptSpec{LLM: llm, Tools: []Tool{{Name: "search"}, {Name: "search"}}}, fields: []string{"spec.tools[1].name"}},
+		{
+			name: "value and value from",
+			spec: PromptSpec{LLM: llm, Template: &PromptTemplateRef{Name: "tpl", Variables: []TemplateVariable{
+				{Name: "a", Value: "x", ValueFrom: &TemplateVariableSource{SecretKeyRef: secretRef}},
+			}}},
+			fields: []string{"spec.template.variables[0].valueFrom"},
+		},
+		{
+			name: "value from no ref",
+			spec: PromptSpec{LLM: llm, Template: &PromptTemplateRef{Name: "tpl", Variables: []TemplateVariable{
+				{Name: "a", ValueFrom: &TemplateVariableSource{}},
+			}}},
+			fields: []string{"spec.template.variables[0].valueFrom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := &Prompt{ObjectMeta: metav1.ObjectMeta{Name: "prompt"}, Spec: tt.spec}
+			_, err := (&promptWebhook{}).ValidateCreate(context.Background(), prompt)
+			expectInvalid(t, err, tt.fields...)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+// Render executes the message templates with variables.
+// A variable used by the templates but missing in variables is an error.
+func (pt PromptTemplate) Render(variables map[string]string) ([]llms.Message, error) {
+	messages := make([]llms.Message, 0, len(pt.Spec.Messages))
+	for i, msg := range pt.Spec.Messages {
+		tmpl, err := template.New(fmt.Sprintf("%s.messages[%d]", pt.Name, i)).Option("missingkey=error").Parse(msg.Content)
+		if err != nil {
+			return nil, err
+		}
+		content := &bytes.Buffer{}
+		if err := tmpl.Execute(content, variables); err != nil {
+			return nil, err
+		}
+		messages = append(messages, llms.Message{Role: msg.Role, Content: content.String()})
+	}
+	return messages, nil
+}
+
+// RenderedHash returns the sha256 of rendered messages
+func RenderedHash(messages []llms.Message) string {
+	data, _ := json.Marshal(messages)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ResolveVariables reads the values of the template variables in namespace ns
+func (ref PromptTemplateRef) ResolveVariables(ctx context.Context, ns string, c client.Client) (map[string]string, error) {
+	variables := make(map[string]string, len(ref.Variables))
+	for _, variable := range ref.Variables {
+		value, err := variable.resolve(ctx, ns, c)
+		if err != nil {
+			return nil, fmt.Errorf("resolve template variable %s: %w", variable.Name, err)
+		}
+		variables[variable.Name] = value
+	}
+	return variables, nil
+}
+
+func (v TemplateVariable) resolve(ctx context.Context, ns string, c client.Client) (string, error) {
+	switch {
+	case v.ValueFrom == nil:
+		return v.Value, nil
+	case v.ValueFrom.ConfigMapKeyRef != nil:
+		ref := v.ValueFrom.ConfigMapKeyRef
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ns}, cm); err != nil {
+			if apierrors.IsNotFound(err) && lo.FromPtr(ref.Optional) {
+				return "", nil
+			}
+			return "", err
+		}
+		if value, ok := cm.Data[ref.Key]; ok {
+			return value, nil
+		}
+		if value, ok := cm.BinaryData[ref.Key]; ok {
+			return string(value), nil
+		}
+		if lo.FromPtr(ref.Optional) {
+			return "", nil
+		}
+		return "", fmt.Errorf("configmap %s has no key %s", ref.Name, ref.Key)
+	case v.ValueFrom.SecretKeyRef != nil:
+		ref := v.ValueFrom.SecretKeyRef
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ns}, secret); err != nil {
+			if apierrors.IsNotFound(err) && lo.FromPtr(ref.Optional) {
+				return "", nil
+			}
+			return "", err
+		}
+		if value, ok := secret.Data[ref.Key]; ok {
+			return string(value), nil
+		}
+		if lo.FromPtr(ref.Optional) {
+			return "", nil
+		}
+		return "", fmt.Errorf("secret %s has no key %s", ref.Name, ref.Key)
+	}
+	return "", fmt.Errorf("valueFrom sets neither configMapKeyRef nor secretKeyRef")
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+func TestPromptTemplateRender(t *testing.T) {
+	pt := PromptTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "guide"},
+		Spec: PromptTemplateSpec{Messages: []TemplateMessage{
+			{Role: llms.MessageRoleSystem, Content: "You are a {{ .role }}."},
+			{Role: llms.MessageRoleUser, Content: "Plan a trip to {{ .city }}."},
+		}},
+	}
+	got, err := pt.Render(map[string]string{"role": "travel guide", "city": "Rome"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []llms.Message{
+		{Role: llms.MessageRoleSystem, Content: "You are a travel guide."},
+		{Role: llms.MessageRoleUser, Content: "Plan a trip to Rome."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected messages %+v, got %+v", want, got)
+	}
+	if _, err := pt.Render(map[string]string{"role": "travel guide"}); err == nil {
+		t.Error("expected an error for the missing variable city")
+	}
+}
+
+func TestPromptTemplateRefResolveVariables(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "trip", Namespace: "default"}, Data: map[string]string{"city": "Rome"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "trip", Namespace: "default"}, Data: map[string][]byte{"budget": []byte("1000")}},
+	).Build()
+	configMapKey := func(key string, optional bool) *TemplateVariableSource {
+		return &TemplateVariableSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "trip"}, Key: key, Optional: lo.ToPtr(optional)}}
+	}
+	secretKey := func(name string) *TemplateVariableSource {
+		return &TemplateVariableSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: "budget"}}
+	}
+
+	tests := []struct {
+		name      string
+		variables []TemplateVariable
+		want      map[string]string
+		wantErr   bool
+	}{
+		{
+			name: "values",
+			variables: []TemplateVariable{
+				{Name: "role", Value: "travel guide"},
+				{Name: "city", ValueFrom: configMapKey("city", false)},
+				{Name: "budget", ValueFrom: secretKey("trip")},
+			},
+			want: map[string]string{"role": "travel guide", "city": "Rome", "budget": "1000"},
+		},
+		{name: "missing key", variables: []TemplateVariable{{Name: "country", ValueFrom: configMapKey("country", false)}}, wantErr: true},
+		{name: "optional key", variables: []TemplateVariable{{Name: "country", ValueFrom: configMapKey("country", true)}}, want: map[string]string{"country": ""}},
+		{name: "missing secret", variables: []TemplateVariable{{Name: "budget", ValueFrom: secretKey("other")}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := PromptTemplateRef{Name: "guide", Variables: tt.variables}
+			got, err := ref.ResolveVariables(context.Background(), "default", c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected variables %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
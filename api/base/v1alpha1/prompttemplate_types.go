@@ -0,0 +1,60 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PromptTemplateSpec defines the desired state of PromptTemplate
+type PromptTemplateSpec struct {
+	// Messages rendered ahead of the messages in the params of a prompt
+	// +kubebuilder:validation:MinItems=1
+	Messages []TemplateMessage `json:"messages"`
+}
+
+// TemplateMessage is a chat message whose content is a go template
+type TemplateMessage struct {
+	// Role of the message, it is converted into the role of the llm type
+	// +kubebuilder:validation:Enum=system;user;assistant
+	Role string `json:"role"`
+	// Content is a go template, variables of the prompt are accessed by {{ .name }}
+	Content string `json:"content"`
+}
+
+//+kubebuilder:object:root=true
+
+// PromptTemplate is the Schema for the prompttemplates API
+type PromptTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PromptTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PromptTemplateList contains a list of PromptTemplate
+type PromptTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PromptTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PromptTemplate{}, &PromptTemplateList{})
+}
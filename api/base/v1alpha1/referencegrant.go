@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrReferenceNotGranted is returned when no reference grant allows a cross-namespace reference
+var ErrReferenceNotGranted = errors.New("reference not granted")
+
+// Allows tells whether the grant lets from refer to to
+func (spec ReferenceGrantSpec) Allows(from ReferenceGrantFrom, to ReferenceGrantTo) bool {
+	return lo.ContainsBy(spec.From, func(f ReferenceGrantFrom) bool {
+		return f.group() == from.group() && f.Kind == from.Kind && f.Namespace == from.Namespace
+	}) && lo.ContainsBy(spec.To, func(t ReferenceGrantTo) bool {
+		return t.group() == to.group() && t.Kind == to.Kind && (t.Name == "" || t.Name == to.Name)
+	})
+}
+
+func (f ReferenceGrantFrom) group() string {
+	return lo.Ternary(f.Group == "", Group, f.Group)
+}
+
+func (t ReferenceGrantTo) group() string {
+	return lo.Ternary(t.Group == "", Group, t.Group)
+}
+
+// CheckReference returns ErrReferenceNotGranted unless the referent to in namespace
+// is in the namespace of the referrer or a reference grant of namespace allows from to refer to it
+func CheckReference(ctx context.Context, c client.Client, from ReferenceGrantFrom, namespace string, to ReferenceGrantTo) error {
+	if from.Namespace == namespace {
+		return nil
+	}
+	list := &ReferenceGrantList{}
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	if lo.ContainsBy(list.Items, func(grant ReferenceGrant) bool { return grant.Spec.Allows(from, to) }) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s of namespace %s may not refer to %s %s/%s", ErrReferenceNotGranted,
+		from.Kind, from.Namespace, to.Kind, namespace, to.Name)
+}
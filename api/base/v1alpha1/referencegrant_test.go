@@ -0,0 +1,73 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckReference(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "prompts", Namespace: "shared"},
+			Spec: ReferenceGrantSpec{
+				From: []ReferenceGrantFrom{{Kind: "Prompt", Namespace: "team-a"}},
+				To:   []ReferenceGrantTo{{Kind: "LLM", Name: "claude"}},
+			},
+		},
+		&ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "shared"},
+			Spec: ReferenceGrantSpec{
+				From: []ReferenceGrantFrom{{Group: Group, Kind: "Worker", Namespace: "team-b"}},
+				To:   []ReferenceGrantTo{{Group: Group, Kind: "Model"}},
+			},
+		},
+	).Build()
+
+	prompt := ReferenceGrantFrom{Kind: "Prompt", Namespace: "team-a"}
+	tests := []struct {
+		name      string
+		from      ReferenceGrantFrom
+		namespace string
+		to        ReferenceGrantTo
+		wantErr   bool
+	}{
+		{name: "same namespace", from: prompt, namespace: "team-a", to: ReferenceGrantTo{Kind: "LLM", Name: "gpt"}},
+		{name: "granted name", from: prompt, namespace: "shared", to: ReferenceGrantTo{Kind: "LLM", Name: "claude"}},
+		{name: "other name", from: prompt, namespace: "shared", to: ReferenceGrantTo{Kind: "LLM", Name: "gpt"}, wantErr: true},
+		{name: "other namespace of referrer", from: ReferenceGrantFrom{Kind: "Prompt", Namespace: "team-c"}, namespace: "shared", to: ReferenceGrantTo{Kind: "LLM", Name: "claude"}, wantErr: true},
+		{name: "all names of a kind", from: ReferenceGrantFrom{Kind: "Worker", Namespace: "team-b"}, namespace: "shared", to: ReferenceGrantTo{Kind: "Model", Name: "qwen"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckReference(context.Background(), c, tt.from, tt.namespace, tt.to)
+			if tt.wantErr != errors.Is(err, ErrReferenceNotGranted) || (!tt.wantErr && err != nil) {
+				t.Errorf("expected not granted %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
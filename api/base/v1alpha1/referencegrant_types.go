@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReferenceGrantSpec defines which objects of other namespaces may refer to objects in the namespace of the grant
+type ReferenceGrantSpec struct {
+	// From are the namespaces and kinds of the objects allowed to refer
+	// +kubebuilder:validation:MinItems=1
+	From []ReferenceGrantFrom `json:"from"`
+
+	// To are the kinds and optionally the names of the objects which may be referred to
+	// +kubebuilder:validation:MinItems=1
+	To []ReferenceGrantTo `json:"to"`
+}
+
+// ReferenceGrantFrom describes the referrers a grant trusts
+type ReferenceGrantFrom struct {
+	// Group of the referrer
+	// +kubebuilder:default=base.fleezesd.io
+	Group string `json:"group,omitempty"`
+	// Kind of the referrer
+	// +kubebuilder:validation:Enum=Prompt;LLM;Worker;Model
+	Kind string `json:"kind"`
+	// Namespace of the referrer
+	Namespace string `json:"namespace"`
+}
+
+// ReferenceGrantTo describes the objects a grant exposes
+type ReferenceGrantTo struct {
+	// Group of the referent
+	// +kubebuilder:default=base.fleezesd.io
+	Group string `json:"group,omitempty"`
+	// Kind of the referent
+	// +kubebuilder:validation:Enum=LLM;Worker;Model;DataSource
+	Kind string `json:"kind"`
+	// Name of the referent, all objects of the kind are granted if it is empty
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ReferenceGrant is the Schema for the referencegrants API.
+// It lives in the namespace of the referents and allows the listed referrers of other namespaces to use them.
+type ReferenceGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ReferenceGrantSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ReferenceGrantList contains a list of ReferenceGrant
+type ReferenceGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReferenceGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReferenceGrant{}, &ReferenceGrantList{})
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// admissionContext returns the context of an admission request of operation by username
+func admissionContext(operation admissionv1.Operation, username string) context.Context {
+	return admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			UserInfo:  authenticationv1.UserInfo{Username: username},
+		},
+	})
+}
+
+// expectInvalid checks that err is nil if no fields are given, or an Invalid error on exactly these fields
+func expectInvalid(t *testing.T, err error, fields ...string) {
+	t.Helper()
+	if len(fields) == 0 {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return
+	}
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) || !apierrors.IsInvalid(err) {
+		t.Errorf("expected an invalid error on %v, got %v", fields, err)
+		return
+	}
+	var got []string
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		got = append(got, cause.Field)
+	}
+	if !slices.Equal(got, fields) {
+		t.Errorf("expected invalid fields %v, got %v: %v", fields, got, err)
+	}
+}
+
+func TestSetCreator(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		creator string
+		want    string
+	}{
+		{name: "create", ctx: admissionContext(admissionv1.Create, "admin"), want: "admin"},
+		{name: "creator is kept", ctx: admissionContext(admissionv1.Create, "admin"), creator: "owner", want: "owner"},
+		{name: "update", ctx: admissionContext(admissionv1.Update, "admin")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &CommonSpec{Creator: tt.creator}
+			setCreator(tt.ctx, spec)
+			if spec.Creator != tt.want {
+				t.Errorf("expected creator %q, got %q", tt.want, spec.Creator)
+			}
+		})
+	}
+}
@@ -16,6 +16,15 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
 type WorkerType string
 
 // todo: make worker type for more llm model use
@@ -25,3 +34,105 @@ const (
 	WorkerTypeKubeAGI        WorkerType = "kubeagi"
 	WorkerTypeUnknown        WorkerType = "unknown"
 )
+
+// ReportsLoad reports whether workers of the type expose metrics of their load, which autoscaling is based on
+func (t WorkerType) ReportsLoad() bool {
+	return t == WorkerTypeFastchatVLLM
+}
+
+// ModelKey returns the namespaced name of the model served by the worker
+func (worker Worker) ModelKey() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: lo.FromPtrOr(worker.Spec.Model.Namespace, worker.Namespace),
+		Name:      worker.Spec.Model.Name,
+	}
+}
+
+// SharedStorage reports whether the replicas of the worker can share its model storage,
+// a claim which is not ReadWriteMany is mounted by one node only
+func (worker Worker) SharedStorage() bool {
+	storage := worker.Spec.Storage
+	return storage == nil || lo.Contains(storage.AccessModes, corev1.ReadWriteMany)
+}
+
+// defaults of Autoscaling
+const (
+	DefaultScaleDownDelay   = 5 * time.Minute
+	DefaultScaleToZeroAfter = 15 * time.Minute
+)
+
+// GetMinReplicas returns the replicas the autoscaler keeps at least
+func (a Autoscaling) GetMinReplicas() int32 {
+	return lo.FromPtrOr(a.MinReplicas, 1)
+}
+
+// GetScaleDownDelay returns the time since the last scaling before replicas are removed
+func (a Autoscaling) GetScaleDownDelay() time.Duration {
+	if a.ScaleDownDelay == nil || a.ScaleDownDelay.Duration <= 0 {
+		return DefaultScaleDownDelay
+	}
+	return a.ScaleDownDelay.Duration
+}
+
+// GetScaleToZeroAfter returns the idle time after which the worker is scaled to zero if MinReplicas is 0
+func (a Autoscaling) GetScaleToZeroAfter() time.Duration {
+	if a.ScaleToZeroAfter == nil || a.ScaleToZeroAfter.Duration <= 0 {
+		return DefaultScaleToZeroAfter
+	}
+	return a.ScaleToZeroAfter.Duration
+}
+
+// ReadyCondition is the condition of a worker which serves its model
+func (worker Worker) ReadyCondition(msg string) Condition {
+	return worker.condition(TypeReady, corev1.ConditionTrue, ReasonAvailable, msg)
+}
+
+// PendingCondition is the condition of a worker waiting for its pods to become ready
+func (worker Worker) PendingCondition(msg string) Condition {
+	return worker.condition(TypeReady, corev1.ConditionFalse, ReasonPending, msg)
+}
+
+// OfflineCondition is the condition of a worker scaled to zero replicas
+func (worker Worker) OfflineCondition() Condition {
+	return worker.condition(TypeReady, corev1.ConditionFalse, ReasonOffline, "worker is scaled to zero")
+}
+
+// ErrorCondition is the condition of a worker which can not serve its model
+func (worker Worker) ErrorCondition(msg string) Condition {
+	return worker.condition(TypeReady, corev1.ConditionFalse, ReasonUnavailable, msg)
+}
+
+// LoadingCondition is the condition of a worker whose loader is downloading the model files
+func (worker Worker) LoadingCondition(msg string) Condition {
+	return worker.condition(TypeLoaded, corev1.ConditionFalse, ReasonDataLoading, msg)
+}
+
+// LoadedCondition is the condition of a worker whose model files are in its storage
+func (worker Worker) LoadedCondition(msg string) Condition {
+	return worker.condition(TypeLoaded, corev1.ConditionTrue, ReasonDataLoadSuccess, msg)
+}
+
+// LoadErrorCondition is the condition of a worker whose loader failed
+func (worker Worker) LoadErrorCondition(msg string) Condition {
+	return worker.condition(TypeLoaded, corev1.ConditionFalse, ReasonDataLoadError, msg)
+}
+
+// condition returns a condition of conditionType, the current one is kept if nothing changed
+func (worker Worker) condition(conditionType ConditionType, status corev1.ConditionStatus, reason ConditionReason, msg string) Condition {
+	currCon := worker.Status.GetCondition(conditionType)
+	if currCon.Status == status && currCon.Reason == reason && currCon.Message == msg {
+		return currCon
+	}
+	cond := Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		LastTransitionTime: metav1.Now(),
+		LastSuccessfulTime: currCon.LastSuccessfulTime,
+	}
+	if status == corev1.ConditionTrue {
+		cond.LastSuccessfulTime = metav1.Now()
+	}
+	return cond
+}
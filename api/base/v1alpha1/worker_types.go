@@ -41,11 +41,16 @@ type WorkerSpec struct {
 	// Model this worker wants to use
 	Model *corev1.TypedObjectReference `json:"model"`
 
-	// Replicas of this worker instance(1 by default)
+	// Replicas of this worker instance(1 by default), they are managed by Autoscaling if it is set.
+	// More than 1 replica requires ReadWriteMany storage
 	// +kubebuilder:default=1
-	// +kubebuilder:validation:Maximum=1
+	// +kubebuilder:validation:Minimum=0
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// Autoscaling scales the replicas with the load of the runners, only fastchat-vllm workers report their load
+	// +optional
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+
 	// Resource request&limits including
 	// - CPU or GPU
 	// - Memory
@@ -64,12 +69,56 @@ type WorkerSpec struct {
 	Runner Image `json:"runner,omitempty"`
 }
 
+// Autoscaling scales the replicas of a worker with the load reported by the prometheus metrics of its runners.
+// At least one of the targets must be set, the replicas needed by the most demanding one are used.
+type Autoscaling struct {
+	// MinReplicas of the worker. With 0 the worker is scaled to zero once it is idle for ScaleToZeroAfter,
+	// and woken up again by the first prompt calling it
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas of the worker
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetInFlightRequests per replica, counting the running and the waiting requests
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TargetInFlightRequests int32 `json:"targetInFlightRequests,omitempty"`
+
+	// TargetTokensPerSecond generated per replica
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TargetTokensPerSecond int32 `json:"targetTokensPerSecond,omitempty"`
+
+	// ScaleDownDelay is the time since the last scaling before replicas are removed
+	// +kubebuilder:default="5m"
+	// +optional
+	ScaleDownDelay *metav1.Duration `json:"scaleDownDelay,omitempty"`
+
+	// ScaleToZeroAfter is the time without requests after which a worker with MinReplicas 0 is scaled to zero
+	// +kubebuilder:default="15m"
+	// +optional
+	ScaleToZeroAfter *metav1.Duration `json:"scaleToZeroAfter,omitempty"`
+}
+
 // WorkerStatus defines the observed state of Worker
 type WorkerStatus struct {
 	// PodStatus is the observed stated of Worker pod
 	// +optional
 	PodStatus corev1.PodStatus `json:"podStatus,omitempty"`
 
+	// Endpoint is the openai compatible api exposed by the worker service,
+	// it is only set if the runner of the worker type serves one
+	// +optional
+	Endpoint *Endpoint `json:"endpoint,omitempty"`
+
+	// LastScaleTime is the time the autoscaler last changed the replicas
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
 	// ConditionedStatus is the current status
 	ConditionedStatus `json:",inline"`
 }
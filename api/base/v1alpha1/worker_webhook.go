@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the webhooks of Worker with the manager
+func (r *Worker) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&workerWebhook{}).
+		WithValidator(&workerWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-base-fleezesd-io-v1alpha1-worker,mutating=true,failurePolicy=fail,sideEffects=None,groups=base.fleezesd.io,resources=workers,verbs=create;update,versions=v1alpha1,name=mworker.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-base-fleezesd-io-v1alpha1-worker,mutating=false,failurePolicy=fail,sideEffects=None,groups=base.fleezesd.io,resources=workers,verbs=create;update,versions=v1alpha1,name=vworker.kb.io,admissionReviewVersions=v1
+
+// workerWebhook defaults and validates Workers
+// +kubebuilder:object:generate=false
+type workerWebhook struct{}
+
+var (
+	_ webhook.CustomDefaulter = &workerWebhook{}
+	_ webhook.CustomValidator = &workerWebhook{}
+)
+
+// Default implements webhook.CustomDefaulter
+func (w *workerWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	worker, ok := obj.(*Worker)
+	if !ok {
+		return fmt.Errorf("expected a Worker but got %T", obj)
+	}
+	webhooklog.V(1).Info("default", "worker", worker.Name)
+
+	setCreator(ctx, &worker.Spec.CommonSpec)
+	if worker.Spec.Type == "" {
+		worker.Spec.Type = WorkerTypeFastchatNormal
+	}
+	return nil
+}
+
+// ValidateCreate implements webhook.CustomValidator
+func (w *workerWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return w.validate(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (w *workerWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return w.validate(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (w *workerWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *workerWebhook) validate(obj runtime.Object) (admission.Warnings, error) {
+	worker, ok := obj.(*Worker)
+	if !ok {
+		return nil, fmt.Errorf("expected a Worker but got %T", obj)
+	}
+	webhooklog.V(1).Info("validate", "worker", worker.Name)
+
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+	if worker.Spec.Model == nil {
+		errs = append(errs, field.Required(spec.Child("model"), "the model to serve is required"))
+	} else if worker.Spec.Model.Name == "" {
+		errs = append(errs, field.Required(spec.Child("model", "name"), ""))
+	}
+	switch worker.Spec.Type {
+	case "", WorkerTypeFastchatNormal, WorkerTypeFastchatVLLM, WorkerTypeKubeAGI:
+	default:
+		errs = append(errs, field.NotSupported(spec.Child("type"), worker.Spec.Type,
+			[]string{string(WorkerTypeFastchatNormal), string(WorkerTypeFastchatVLLM), string(WorkerTypeKubeAGI)}))
+	}
+	if replicas := lo.FromPtr(worker.Spec.Replicas); replicas > 1 && !worker.SharedStorage() {
+		errs = append(errs, field.Invalid(spec.Child("replicas"), replicas, "more than 1 replica requires ReadWriteMany storage"))
+	}
+	if a := worker.Spec.Autoscaling; a != nil {
+		path := spec.Child("autoscaling")
+		if !worker.Spec.Type.ReportsLoad() {
+			errs = append(errs, field.Forbidden(path, fmt.Sprintf("workers of type %q report no load to autoscale with", worker.Spec.Type)))
+		}
+		if a.MaxReplicas < a.GetMinReplicas() {
+			errs = append(errs, field.Invalid(path.Child("maxReplicas"), a.MaxReplicas, "must not be less than minReplicas"))
+		}
+		if a.MaxReplicas > 1 && !worker.SharedStorage() {
+			errs = append(errs, field.Invalid(path.Child("maxReplicas"), a.MaxReplicas, "more than 1 replica requires ReadWriteMany storage"))
+		}
+		if a.TargetInFlightRequests == 0 && a.TargetTokensPerSecond == 0 {
+			errs = append(errs, field.Required(path, "one of targetInFlightRequests and targetTokensPerSecond is required"))
+		}
+	}
+	return nil, invalid(KindWorker, worker.Name, errs)
+}
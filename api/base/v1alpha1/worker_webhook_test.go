@@ -0,0 +1,70 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkerWebhookDefault(t *testing.T) {
+	worker := &Worker{}
+	if err := (&workerWebhook{}).Default(context.Background(), worker); err != nil {
+		t.Fatal(err)
+	}
+	if worker.Spec.Type != WorkerTypeFastchatNormal {
+		t.Errorf("expected type %q, got %q", WorkerTypeFastchatNormal, worker.Spec.Type)
+	}
+}
+
+func TestWorkerWebhookValidate(t *testing.T) {
+	model := &corev1.TypedObjectReference{Name: "model"}
+	rwo := &corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}}
+	rwx := &corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}}
+	autoscaling := &Autoscaling{MinReplicas: lo.ToPtr[int32](0), MaxReplicas: 2, TargetInFlightRequests: 8}
+	tests := []struct {
+		name   string
+		spec   WorkerSpec
+		fields []string
+	}{
+		{name: "model", spec: WorkerSpec{Model: model}},
+		{name: "no model", spec: WorkerSpec{}, fields: []string{"spec.model"}},
+		{name: "unknown type", spec: WorkerSpec{Model: model, Type: "tgi"}, fields: []string{"spec.type"}},
+		{name: "autoscaling", spec: WorkerSpec{Model: model, Type: WorkerTypeFastchatVLLM, Autoscaling: autoscaling}},
+		{name: "autoscaling without load", spec: WorkerSpec{Model: model, Type: WorkerTypeFastchatNormal, Autoscaling: autoscaling}, fields: []string{"spec.autoscaling"}},
+		{
+			name:   "max replicas below min replicas",
+			spec:   WorkerSpec{Model: model, Type: WorkerTypeFastchatVLLM, Autoscaling: &Autoscaling{MinReplicas: lo.ToPtr[int32](3), MaxReplicas: 2, TargetTokensPerSecond: 100}},
+			fields: []string{"spec.autoscaling.maxReplicas"},
+		},
+		{name: "no target", spec: WorkerSpec{Model: model, Type: WorkerTypeFastchatVLLM, Autoscaling: &Autoscaling{MaxReplicas: 2}}, fields: []string{"spec.autoscaling"}},
+		{name: "replicas on shared storage", spec: WorkerSpec{Model: model, Replicas: lo.ToPtr[int32](2), Storage: rwx}},
+		{name: "replicas on rwo storage", spec: WorkerSpec{Model: model, Replicas: lo.ToPtr[int32](2), Storage: rwo}, fields: []string{"spec.replicas"}},
+		{name: "autoscaling on rwo storage", spec: WorkerSpec{Model: model, Type: WorkerTypeFastchatVLLM, Storage: rwo, Autoscaling: autoscaling}, fields: []string{"spec.autoscaling.maxReplicas"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := &Worker{ObjectMeta: metav1.ObjectMeta{Name: "worker"}, Spec: tt.spec}
+			_, err := (&workerWebhook{}).ValidateCreate(context.Background(), worker)
+			expectInvalid(t, err, tt.fields...)
+		})
+	}
+}
@@ -17,18 +17,22 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -38,6 +42,15 @@ import (
 	llmv1alpha1 "github.com/fleezesd/llm-operator/api/v1alpha1"
 	"github.com/fleezesd/llm-operator/internal/controller"
 	basecontroller "github.com/fleezesd/llm-operator/internal/controller/base"
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/fleezesd/llm-operator/pkg/worker"
+
+	// Register built-in llm providers. In-house providers are registered
+	// the same way by importing their packages here.
+	_ "github.com/fleezesd/llm-operator/pkg/llms/models/anthropic"
+	_ "github.com/fleezesd/llm-operator/pkg/llms/models/deepseek"
+	_ "github.com/fleezesd/llm-operator/pkg/llms/models/gemini"
+	_ "github.com/fleezesd/llm-operator/pkg/llms/models/openai"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -60,6 +73,7 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var promptConcurrency int
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -68,6 +82,8 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&secureMetrics, "secure-metrics", false, "If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false, "If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.IntVar(&promptConcurrency, "prompt-concurrency", 4,
+		"The number of prompts called at the same time, it bounds the maxConcurrency of the llms.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -87,6 +103,10 @@ func main() {
 	}
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		// secrets are read from the api server, only their metadata is cached for the watches
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}},
+		},
 		Metrics: metricsserver.Options{
 			BindAddress:   metricsAddr,
 			SecureServing: secureMetrics,
@@ -115,6 +135,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = basecontroller.SetupIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to set up field indexes")
+		os.Exit(1)
+	}
 	if err = (&controller.ModelReconciler{
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
@@ -123,23 +147,29 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "Model")
 		os.Exit(1)
 	}
+	// the rate limits are enforced by the prompt controller, the llm controller drops the limiters of deleted llms
+	limiters := llms.NewLimiters()
 	if err = (&basecontroller.LLMReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Limiters: limiters,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LLM")
 		os.Exit(1)
 	}
 	if err = (&basecontroller.PromptReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Limiters:                limiters,
+		MaxConcurrentReconciles: promptConcurrency,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Prompt")
 		os.Exit(1)
 	}
 	if err = (&basecontroller.WorkerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Autoscaler: worker.NewAutoscaler(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Worker")
 		os.Exit(1)
@@ -158,6 +188,23 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "DataSource")
 		os.Exit(1)
 	}
+	// the webhooks need serving certificates, set ENABLE_WEBHOOKS=false to run the manager without them, e.g. locally
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		for _, obj := range []interface {
+			SetupWebhookWithManager(ctrl.Manager) error
+		}{
+			&basev1alpha1.LLM{},
+			&basev1alpha1.Prompt{},
+			&basev1alpha1.Worker{},
+			&basev1alpha1.Model{},
+			&basev1alpha1.DataSource{},
+		} {
+			if err = obj.SetupWebhookWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", fmt.Sprintf("%T", obj))
+				os.Exit(1)
+			}
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -7,6 +7,9 @@ const (
 	waitLonger  = time.Hour
 	waitSmaller = time.Second * 3
 	waitMedium  = time.Minute
+
+	// streamStatusInterval is the minimum interval between partial output patches
+	streamStatusInterval = time.Second * 2
 )
 
 const (
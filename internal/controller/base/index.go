@@ -0,0 +1,158 @@
+package base
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
+	"github.com/samber/lo"
+)
+
+// field indexes shared by the controllers
+const (
+	// llmAuthSecretIndex indexes llms by the name of their endpoint auth secret
+	llmAuthSecretIndex = "spec.endpoint.authSecret.name"
+	// llmGroupBackendIndex indexes llm groups by the names of their backends
+	llmGroupBackendIndex = "spec.provider.group.backends.name"
+	// llmWorkerIndex indexes llms by the namespaced name of their worker
+	llmWorkerIndex = "spec.provider.worker"
+	// workerModelIndex indexes workers by the namespaced name of their model
+	workerModelIndex = "spec.model"
+	// modelSourceIndex indexes models by the namespaced name of their datasource
+	modelSourceIndex = "spec.source"
+	// promptLLMIndex indexes prompts by the namespaced name of their llm
+	promptLLMIndex = "spec.llm"
+)
+
+// SetupIndexes registers the field indexes the controllers look up related objects with.
+// It must be called before the controllers are set up.
+func SetupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &basev1alpha1.LLM{}, llmAuthSecretIndex, func(o client.Object) []string {
+		llm := o.(*basev1alpha1.LLM)
+		if lo.IsNil(llm.Spec.Endpoint) || lo.IsNil(llm.Spec.Endpoint.AuthSecret) {
+			return nil
+		}
+		return []string{llm.Spec.Endpoint.AuthSecret.Name}
+	}); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &basev1alpha1.LLM{}, llmGroupBackendIndex, func(o client.Object) []string {
+		llm := o.(*basev1alpha1.LLM)
+		if lo.IsNil(llm.Spec.Group) {
+			return nil
+		}
+		return lo.Uniq(lo.Map(llm.Spec.Group.Backends, func(backend basev1alpha1.LLMBackend, _ int) string { return backend.Name }))
+	}); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &basev1alpha1.LLM{}, llmWorkerIndex, func(o client.Object) []string {
+		llm := o.(*basev1alpha1.LLM)
+		if lo.IsNil(llm.Spec.Worker) {
+			return nil
+		}
+		return []string{llmWorkerKey(llm).String()}
+	}); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &basev1alpha1.Worker{}, workerModelIndex, func(o client.Object) []string {
+		worker := o.(*basev1alpha1.Worker)
+		if lo.IsNil(worker.Spec.Model) {
+			return nil
+		}
+		return []string{worker.ModelKey().String()}
+	}); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &basev1alpha1.Model{}, modelSourceIndex, func(o client.Object) []string {
+		model := o.(*basev1alpha1.Model)
+		if lo.IsNil(model.Spec.Source) {
+			return nil
+		}
+		return []string{model.SourceKey().String()}
+	}); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &basev1alpha1.Prompt{}, promptLLMIndex, func(o client.Object) []string {
+		prompt := o.(*basev1alpha1.Prompt)
+		if lo.IsNil(prompt.Spec.LLM) {
+			return nil
+		}
+		return []string{promptLLMKey(prompt)}
+	})
+}
+
+// promptLLMKey returns the index key of the llm a prompt calls
+func promptLLMKey(prompt *basev1alpha1.Prompt) string {
+	return types.NamespacedName{
+		Namespace: lo.FromPtrOr(prompt.Spec.LLM.Namespace, prompt.Namespace),
+		Name:      prompt.Spec.LLM.Name,
+	}.String()
+}
+
+// llmWorkerKey returns the namespaced name of the worker serving llm
+func llmWorkerKey(llm *basev1alpha1.LLM) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: lo.FromPtrOr(llm.Spec.Worker.Namespace, llm.Namespace),
+		Name:      llm.Spec.Worker.Name,
+	}
+}
+
+// llmsForWorker returns the llms served by worker, they may live in any namespace
+func llmsForWorker(ctx context.Context, c client.Client, worker client.Object) []basev1alpha1.LLM {
+	list := &basev1alpha1.LLMList{}
+	if err := c.List(ctx, list, client.MatchingFields{llmWorkerIndex: client.ObjectKeyFromObject(worker).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list llms of worker", "worker", client.ObjectKeyFromObject(worker))
+		return nil
+	}
+	return list.Items
+}
+
+// workersForModel returns the workers serving model, they may live in any namespace
+func workersForModel(ctx context.Context, c client.Client, model client.Object) []basev1alpha1.Worker {
+	list := &basev1alpha1.WorkerList{}
+	if err := c.List(ctx, list, client.MatchingFields{workerModelIndex: client.ObjectKeyFromObject(model).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list workers of model", "model", client.ObjectKeyFromObject(model))
+		return nil
+	}
+	return list.Items
+}
+
+// modelsForDataSource returns the models whose files are held by ds, they may live in any namespace
+func modelsForDataSource(ctx context.Context, c client.Client, ds client.Object) []basev1alpha1.Model {
+	list := &basev1alpha1.ModelList{}
+	if err := c.List(ctx, list, client.MatchingFields{modelSourceIndex: client.ObjectKeyFromObject(ds).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list models of datasource", "datasource", client.ObjectKeyFromObject(ds))
+		return nil
+	}
+	return list.Items
+}
+
+// llmsForSecret returns the llms authenticating with secret
+func llmsForSecret(ctx context.Context, c client.Client, secret client.Object) []basev1alpha1.LLM {
+	list := &basev1alpha1.LLMList{}
+	if err := c.List(ctx, list, client.InNamespace(secret.GetNamespace()),
+		client.MatchingFields{llmAuthSecretIndex: secret.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list llms of secret", "secret", client.ObjectKeyFromObject(secret))
+		return nil
+	}
+	return list.Items
+}
+
+// groupsForLLM returns the llm groups which route calls to llm
+func groupsForLLM(ctx context.Context, c client.Client, llm client.Object) []basev1alpha1.LLM {
+	list := &basev1alpha1.LLMList{}
+	if err := c.List(ctx, list, client.InNamespace(llm.GetNamespace()),
+		client.MatchingFields{llmGroupBackendIndex: llm.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list groups of llm", "llm", client.ObjectKeyFromObject(llm))
+		return nil
+	}
+	return list.Items
+}
+
+// secretChanged passes secret updates, only the metadata of secrets is watched
+// so the data is not compared, any new resource version may carry new credentials
+var secretChanged = predicate.ResourceVersionChangedPredicate{}
@@ -18,10 +18,19 @@ package base
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"reflect"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -36,7 +45,6 @@ import (
 
 	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
 	"github.com/fleezesd/llm-operator/pkg/llms"
-	"github.com/fleezesd/llm-operator/pkg/llms/models/openai"
 	"github.com/go-logr/logr"
 	"github.com/samber/lo"
 	langchainllms "github.com/tmc/langchaingo/llms"
@@ -46,6 +54,8 @@ import (
 type LLMReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Limiters enforces the rate limits of llms, the limiters of deleted llms are dropped from it
+	Limiters *llms.Limiters
 }
 
 //+kubebuilder:rbac:groups=base.fleezesd.io,resources=llms,verbs=get;list;watch;create;update;patch;delete
@@ -68,6 +78,9 @@ func (r *LLMReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	llm := &basev1alpha1.LLM{}
 	if err := r.Get(ctx, req.NamespacedName, llm); err != nil {
 		logger.V(1).Info("Failed to get LLM")
+		if apierrors.IsNotFound(err) && r.Limiters != nil {
+			r.Limiters.Forget(req.String())
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
@@ -86,6 +99,9 @@ func (r *LLMReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	if llm.GetDeletionTimestamp() != nil && ctrlutil.ContainsFinalizer(llm, basev1alpha1.Finalizer) {
 		logger.Info("Performing Finalizer Operations for LLM before delete CR")
 		// TODO perform the finalizer operations here, for example: remove data?
+		if r.Limiters != nil {
+			r.Limiters.Forget(req.String())
+		}
 		logger.Info("Removing Finalizer for LLM after successfully performing the operations")
 		ctrlutil.RemoveFinalizer(llm, basev1alpha1.Finalizer)
 		if err := r.Update(ctx, llm); err != nil {
@@ -116,8 +132,12 @@ func (r *LLMReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		logger.Error(err, "Failed to check LLM")
 		return ctrl.Result{RequeueAfter: waitMedium}, err
 	}
+	if len(llm.Status.UnavailableModels()) > 0 {
+		// check the broken models again sooner
+		return ctrl.Result{RequeueAfter: min(waitMedium, llm.GetProbeInterval())}, nil
+	}
 
-	return ctrl.Result{RequeueAfter: waitLonger}, nil
+	return ctrl.Result{RequeueAfter: llm.GetProbeInterval()}, nil
 }
 
 // CheckLLM checks if the LLM provider is ready
@@ -129,65 +149,267 @@ func (r *LLMReconciler) CheckLLM(ctx context.Context, logger logr.Logger, instan
 		return r.check3rdPartyLLM(ctx, logger, instance)
 	case basev1alpha1.ProviderTypeWorker:
 		return r.checkWorkerLLM(ctx, logger, instance)
+	case basev1alpha1.ProviderTypeGroup:
+		return r.checkGroupLLM(ctx, logger, instance)
 	}
 	return nil
 }
 
-func (r *LLMReconciler) check3rdPartyLLM(ctx context.Context, logger logr.Logger, llm *basev1alpha1.LLM) error {
-	logger.Info("Checking 3rd party LLM resource")
+// checkGroupLLM checks the readiness of the backends of a llm group.
+// The group is ready as long as one of its backends is.
+func (r *LLMReconciler) checkGroupLLM(ctx context.Context, logger logr.Logger, llm *basev1alpha1.LLM) error {
+	logger.Info("Checking LLM group resource")
 
 	var (
-		err error
-		msg string
+		ready, unready []string
+		backends       []basev1alpha1.LLM
 	)
+	for _, backend := range llm.Spec.Group.Backends {
+		backendLLM := &basev1alpha1.LLM{}
+		err := r.Get(ctx, types.NamespacedName{Name: backend.Name, Namespace: llm.Namespace}, backendLLM)
+		if err == nil {
+			backends = append(backends, *backendLLM)
+		}
+		switch {
+		case err != nil:
+			logger.Info("Backend of LLM group is not available", "backend", backend.Name, "reason", err.Error())
+			unready = append(unready, backend.Name)
+		case backendLLM.Spec.Provider.GetType() == basev1alpha1.ProviderTypeGroup:
+			return r.UpdateStatus(ctx, llm, nil, fmt.Errorf("backend %s is a llm group, groups can not be nested", backend.Name))
+		case backendLLM.IsReady():
+			ready = append(ready, backend.Name)
+		default:
+			unready = append(unready, backend.Name)
+		}
+	}
+	if err := basev1alpha1.CheckBackendTypes(backends); err != nil {
+		return r.UpdateStatus(ctx, llm, nil, err)
+	}
+
+	if len(ready) == 0 {
+		return r.UpdateStatus(ctx, llm, nil, fmt.Errorf("no backend is ready: %s", strings.Join(unready, ", ")))
+	}
+	msg := fmt.Sprintf("%d of %d backends ready", len(ready), len(ready)+len(unready))
+	if len(unready) > 0 {
+		return r.setCondition(ctx, llm, llm.PartiallyReadyCondition(fmt.Sprintf("%s, not ready: %s", msg, strings.Join(unready, ", "))))
+	}
+	return r.UpdateStatus(ctx, llm, msg, nil)
+}
+
+func (r *LLMReconciler) check3rdPartyLLM(ctx context.Context, logger logr.Logger, llm *basev1alpha1.LLM) error {
+	logger.Info("Checking 3rd party LLM resource")
 
 	// check auth availability
-	apiKey, err := llm.AuthAPIKey(ctx, r.Client)
+	opts, err := llm.ClientOptions(ctx, r.Client)
 	if err != nil {
 		return r.UpdateStatus(ctx, llm, nil, err)
 	}
 
-	// get models
-	models := llm.Get3rdPartyModels()
+	provider, err := llms.GetProvider(llm.Spec.Type)
+	if err != nil {
+		return r.UpdateStatus(ctx, llm, nil, err)
+	}
+	llmClient, err := provider.New(opts)
+	if err != nil {
+		return r.UpdateStatus(ctx, llm, nil, err)
+	}
+	probe := &modelProbe{policy: llm.GetRetryPolicy(), provider: provider, client: llmClient}
+	_, canList := llmClient.(llms.ModelLister)
+	probe.mode = llm.GetProbeMode(canList)
+
+	// get models, they are discovered unless listed in spec
+	discovered := false
+	if len(llm.Spec.Models) == 0 || probe.mode == basev1alpha1.ProbeModeListModels {
+		if discovered, err = probe.listModels(ctx, logger); err != nil {
+			return r.UpdateStatus(ctx, llm, nil, err)
+		}
+	}
+	if !discovered && probe.mode == basev1alpha1.ProbeModeListModels {
+		if llm.Spec.Probe != nil && llm.Spec.Probe.Mode == basev1alpha1.ProbeModeListModels {
+			return r.UpdateStatus(ctx, llm, nil, errors.New("model listing is not supported by this llm, choose another probe mode"))
+		}
+		probe.mode = basev1alpha1.ProbeModeMinimalCompletion
+	}
+	if probe.mode == basev1alpha1.ProbeModeCustomPrompt {
+		if err := probe.setPrompt(llm.Spec.Probe); err != nil {
+			return r.UpdateStatus(ctx, llm, nil, err)
+		}
+	}
+
+	models := llm.Spec.Models
+	if len(models) == 0 {
+		models = lo.Ternary(discovered, probe.listed, llm.Get3rdPartyModels())
+	}
+	models = llm.Spec.ModelFilter.Filter(models)
 	if len(models) == 0 {
-		return r.UpdateStatus(ctx, llm, nil, errors.New("no models provided"))
+		return r.UpdateStatus(ctx, llm, nil, errors.New("no models available"))
+	}
+
+	health := make([]basev1alpha1.ModelHealth, 0, len(models))
+	for _, model := range models {
+		health = append(health, probe.check(ctx, model))
 	}
+	llm.Status.Models = models
+	llm.Status.ModelHealth = health
+
+	unavailable := llm.Status.UnavailableModels()
+	if len(unavailable) == len(models) {
+		return r.UpdateStatus(ctx, llm, nil, errors.Join(lo.Map(health, func(model basev1alpha1.ModelHealth, _ int) error {
+			return fmt.Errorf("model %s: %s", model.Name, model.LastError)
+		})...))
+	}
+	msg := fmt.Sprintf("%d of %d models available", len(models)-len(unavailable), len(models))
+	if len(unavailable) > 0 {
+		msg = fmt.Sprintf("%s, unavailable: %s", msg, strings.Join(unavailable, ", "))
+	}
+	return r.UpdateStatus(ctx, llm, msg, nil)
+}
+
+// modelProbe checks the health of the models of a llm
+type modelProbe struct {
+	mode     basev1alpha1.ProbeMode
+	policy   llms.RetryPolicy
+	provider llms.Provider
+	client   llms.LLM
+
+	// listed models and the latency of listing them
+	listed        []string
+	listLatency   time.Duration
+	listCheckTime metav1.Time
+
+	// prompt sent in CustomPrompt mode and the expected response
+	prompt   string
+	expected *regexp.Regexp
+}
+
+// listModels lists the models served by the llm.
+// It reports false if the llm does not support listing its models.
+func (p *modelProbe) listModels(ctx context.Context, logger logr.Logger) (bool, error) {
+	lister, ok := p.client.(llms.ModelLister)
+	if !ok {
+		return false, nil
+	}
+	var models []string
+	_, err := p.policy.Do(ctx, func() error {
+		start := time.Now()
+		var listErr error
+		models, listErr = lister.ListModels(ctx)
+		p.listLatency = time.Since(start)
+		return listErr
+	})
+	var httpErr *llms.HTTPError
+	if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusMethodNotAllowed) {
+		// gateways in front of the llm often do not expose the model listing api
+		logger.Info("Model listing is not supported, fall back to the default models", "reason", err.Error())
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	slices.Sort(models)
+	p.listed, p.listCheckTime = slices.Compact(models), metav1.Now()
+	return true, nil
+}
 
-	switch llm.Spec.Type {
-	case llms.OpenAI:
-		llmClient, err := openai.NewOpenAI(apiKey, llm.Spec.Endpoint.URL)
+// setPrompt prepares the CustomPrompt mode
+func (p *modelProbe) setPrompt(probe *basev1alpha1.Probe) error {
+	if probe == nil || probe.Prompt == "" {
+		return errors.New("probe prompt is required in CustomPrompt mode")
+	}
+	p.prompt = probe.Prompt
+	if probe.ExpectedResponse != "" {
+		expected, err := regexp.Compile(probe.ExpectedResponse)
 		if err != nil {
-			return r.UpdateStatus(ctx, llm, nil, err)
+			return fmt.Errorf("invalid probe expected response: %w", err)
 		}
+		p.expected = expected
+	}
+	return nil
+}
 
-		for _, model := range models {
-			res, err := llmClient.Validate(ctx, langchainllms.WithModel(model))
-			if err != nil {
-				return r.UpdateStatus(ctx, llm, nil, err)
-			}
-			msg = strings.Join([]string{msg, res.String()}, "\n")
+// check probes a model and reports its health
+func (p *modelProbe) check(ctx context.Context, model string) basev1alpha1.ModelHealth {
+	if p.mode == basev1alpha1.ProbeModeListModels {
+		health := basev1alpha1.ModelHealth{Name: model, Available: lo.Contains(p.listed, model), LastCheckTime: p.listCheckTime}
+		if health.Available {
+			health.Latency = &metav1.Duration{Duration: p.listLatency.Round(time.Millisecond)}
+		} else {
+			health.LastError = "model is not listed by the llm"
 		}
-	default:
-		return r.UpdateStatus(ctx, llm, nil, errors.New("unsupported llm type"))
+		return health
 	}
 
-	return r.UpdateStatus(ctx, llm, msg, nil)
+	var latency time.Duration
+	_, err := p.policy.Do(ctx, func() error {
+		start := time.Now()
+		defer func() { latency = time.Since(start) }()
+		if p.mode == basev1alpha1.ProbeModeCustomPrompt {
+			return p.callPrompt(ctx, model)
+		}
+		_, validateErr := p.provider.Validate(ctx, p.client, model, langchainllms.WithMaxTokens(1))
+		return validateErr
+	})
+	health := basev1alpha1.ModelHealth{Name: model, Available: err == nil, LastCheckTime: metav1.Now()}
+	if err != nil {
+		health.LastError = err.Error()
+	} else {
+		health.Latency = &metav1.Duration{Duration: latency.Round(time.Millisecond)}
+	}
+	return health
+}
+
+// callPrompt sends the probe prompt to model and checks the response
+func (p *modelProbe) callPrompt(ctx context.Context, model string) error {
+	params := p.provider.NewParams()
+	raw, err := json.Marshal(map[string]string{"model": model})
+	if err != nil {
+		return err
+	}
+	if err := params.Unmarshal(raw); err != nil {
+		return err
+	}
+	if err := params.PrependMessages(llms.Message{Role: llms.MessageRoleUser, Content: p.prompt}); err != nil {
+		return err
+	}
+	resp, err := p.client.Call(ctx, params.Marshal())
+	if err != nil {
+		return err
+	}
+	if p.expected != nil && !p.expected.MatchString(resp.GetData()) {
+		return fmt.Errorf("unexpected probe response %q", resp.GetData())
+	}
+	return nil
 }
 
 func (r *LLMReconciler) UpdateStatus(ctx context.Context, instance *basev1alpha1.LLM, t interface{}, err error) error {
-	instanceCopy := instance.DeepCopy()
 	var newCondition basev1alpha1.Condition
 	if err != nil {
 		newCondition = instance.ErrorCondition(err.Error())
+		if errors.Is(err, basev1alpha1.ErrInvalidAuthSecret) {
+			newCondition.Reason = basev1alpha1.ReasonInvalidAuth
+		}
 	} else {
 		msg, ok := t.(string)
 		if !ok {
 			msg = statusNilResponse
 		}
 		newCondition = instance.ReadyCondition(msg)
+		if len(instance.Status.UnavailableModels()) > 0 {
+			newCondition = instance.PartiallyReadyCondition(msg)
+		}
 	}
-	instanceCopy.Status.SetConditions(newCondition)
-	return errors.Join(err, r.Client.Status().Update(ctx, instanceCopy))
+	return errors.Join(err, r.setCondition(ctx, instance, newCondition))
+}
+
+// setCondition updates the status of instance with condition
+func (r *LLMReconciler) setCondition(ctx context.Context, instance *basev1alpha1.LLM, condition basev1alpha1.Condition) error {
+	instanceCopy := instance.DeepCopy()
+	instanceCopy.Status.SetConditions(condition)
+	// reprice the recorded usage in case spec.pricing changed
+	if costErr := instanceCopy.EstimateCosts(); costErr != nil {
+		log.FromContext(ctx).Error(costErr, "Failed to estimate llm usage cost")
+	}
+	return r.Client.Status().Update(ctx, instanceCopy)
 }
 
 func (r *LLMReconciler) checkWorkerLLM(ctx context.Context, logger logr.Logger, llm *basev1alpha1.LLM) error {
@@ -198,13 +420,20 @@ func (r *LLMReconciler) checkWorkerLLM(ctx context.Context, logger logr.Logger,
 		msg string
 	)
 
+	key := llmWorkerKey(llm)
+	err = basev1alpha1.CheckReference(ctx, r.Client,
+		basev1alpha1.ReferenceGrantFrom{Kind: basev1alpha1.KindLLM, Namespace: llm.Namespace},
+		key.Namespace, basev1alpha1.ReferenceGrantTo{Kind: basev1alpha1.KindWorker, Name: key.Name})
+	if err != nil {
+		return r.UpdateStatus(ctx, llm, nil, err)
+	}
 	worker := &basev1alpha1.Worker{}
-	err = r.Client.Get(ctx, types.NamespacedName{Namespace: llm.Namespace,
-		Name: llm.Spec.Worker.Name},
-		worker)
+	err = r.Client.Get(ctx, key, worker)
 	if err != nil {
 		return r.UpdateStatus(ctx, llm, nil, err)
 	}
+	// calls are sent to the service of the worker, it has no endpoint if its runner serves no openai compatible api
+	llm.Status.Endpoint = worker.Status.Endpoint.DeepCopy()
 	if !worker.Status.IsReady() {
 		if worker.Status.IsOffline() {
 			return r.UpdateStatus(ctx, llm, nil, errors.New("worker is offline"))
@@ -222,45 +451,87 @@ func (r *LLMReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&basev1alpha1.LLM{}, builder.WithPredicates(
 			LLMPredicates{},
 		)).
-		Watches(&basev1alpha1.Worker{}, handler.EnqueueRequestsFromMapFunc(
+		Watches(&basev1alpha1.Worker{}, handler.EnqueueRequestsFromMapFunc(r.requestsForWorker)).
+		// check llms of other namespaces again once a grant allows them to use the workers
+		Watches(&basev1alpha1.ReferenceGrant{}, handler.EnqueueRequestsFromMapFunc(r.requestsForGrant)).
+		// re-validate llms as soon as their credentials are rotated
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(
 			func(ctx context.Context, o client.Object) []reconcile.Request {
-				// Cast the object to Worker type
-				worker := o.(*basev1alpha1.Worker)
-
-				// Get a deep copy of the model spec from the worker
-				model := worker.Spec.Model.DeepCopy()
-
-				// If namespace is not specified, use the worker's namespace
-				if lo.IsNil(model.Namespace) {
-					model.Namespace = &worker.Namespace
-				}
-
-				// Try to fetch the referenced Model resource
-				m := &basev1alpha1.Model{}
-				if err := r.Client.Get(context.TODO(), types.NamespacedName{
-					Namespace: *model.Namespace,
-					Name:      model.Name,
-				}, m); err != nil {
-					// If model not found, return empty request list
-					return []ctrl.Request{}
-				}
-
-				// If the model is an LLM model, trigger reconciliation
-				if m.IsLLMModel() {
-					return []ctrl.Request{
-						reconcile.Request{
-							NamespacedName: client.ObjectKeyFromObject(o),
-						},
-					}
-				}
-
-				// Otherwise return empty request list
-				return []ctrl.Request{}
+				return lo.Map(llmsForSecret(ctx, r.Client, o), func(llm basev1alpha1.LLM, _ int) reconcile.Request {
+					return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&llm)}
+				})
 			},
-		)).
+		), builder.OnlyMetadata, builder.WithPredicates(secretChanged)).
+		// follow the readiness of the backends of llm groups
+		Watches(&basev1alpha1.LLM{}, handler.EnqueueRequestsFromMapFunc(
+			func(ctx context.Context, o client.Object) []reconcile.Request {
+				return lo.Map(groupsForLLM(ctx, r.Client, o), func(group basev1alpha1.LLM, _ int) reconcile.Request {
+					return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&group)}
+				})
+			},
+		), builder.WithPredicates(llmReadinessChanged)).
 		Complete(r)
 }
 
+// requestsForWorker returns the llms served by a worker which runs a llm model
+func (r *LLMReconciler) requestsForWorker(ctx context.Context, o client.Object) []reconcile.Request {
+	worker := o.(*basev1alpha1.Worker)
+	if lo.IsNil(worker.Spec.Model) {
+		return nil
+	}
+	key := worker.ModelKey()
+	// a model of another namespace is only used by the worker if a grant allows it
+	if err := basev1alpha1.CheckReference(ctx, r.Client,
+		basev1alpha1.ReferenceGrantFrom{Kind: basev1alpha1.KindWorker, Namespace: worker.Namespace},
+		key.Namespace, basev1alpha1.ReferenceGrantTo{Kind: basev1alpha1.KindModel, Name: key.Name}); err != nil {
+		return nil
+	}
+	model := &basev1alpha1.Model{}
+	if err := r.Client.Get(ctx, key, model); err != nil {
+		return nil
+	}
+	if !model.IsLLMModel() {
+		return nil
+	}
+	return lo.Map(llmsForWorker(ctx, r.Client, worker), func(llm basev1alpha1.LLM, _ int) reconcile.Request {
+		return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&llm)}
+	})
+}
+
+// requestsForGrant returns the llms of other namespaces which are allowed by grant to use a worker of its namespace
+func (r *LLMReconciler) requestsForGrant(ctx context.Context, o client.Object) []reconcile.Request {
+	grant := o.(*basev1alpha1.ReferenceGrant)
+	var requests []reconcile.Request
+	for _, from := range grant.Spec.From {
+		if from.Kind != basev1alpha1.KindLLM || from.Namespace == grant.Namespace {
+			continue
+		}
+		list := &basev1alpha1.LLMList{}
+		if err := r.List(ctx, list, client.InNamespace(from.Namespace)); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list llms of grant", "grant", client.ObjectKeyFromObject(grant))
+			continue
+		}
+		for i := range list.Items {
+			llm := &list.Items[i]
+			if !lo.IsNil(llm.Spec.Worker) && llmWorkerKey(llm).Namespace == grant.Namespace {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(llm)})
+			}
+		}
+	}
+	return requests
+}
+
+// llmReadinessChanged passes llm events which may change the readiness of the groups routing to the llm
+var llmReadinessChanged = predicate.Funcs{
+	UpdateFunc: func(ue event.UpdateEvent) bool {
+		oldLLM, ok := ue.ObjectOld.(*basev1alpha1.LLM)
+		if !ok {
+			return false
+		}
+		return oldLLM.IsReady() != ue.ObjectNew.(*basev1alpha1.LLM).IsReady()
+	},
+}
+
 type LLMPredicates struct {
 	predicate.Funcs
 }
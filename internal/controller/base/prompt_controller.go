@@ -18,17 +18,24 @@ package base
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -37,20 +44,32 @@ import (
 
 	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
 	"github.com/fleezesd/llm-operator/pkg/llms"
-	"github.com/fleezesd/llm-operator/pkg/llms/models/openai"
+	"github.com/fleezesd/llm-operator/pkg/worker"
 	"github.com/go-logr/logr"
 	"github.com/samber/lo"
+	langchainllms "github.com/tmc/langchaingo/llms"
 )
 
 // PromptReconciler reconciles a Prompt object
 type PromptReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Limiters enforces the rate limits of llms, no limits are enforced if it is nil
+	Limiters *llms.Limiters
+	// MaxConcurrentReconciles is the number of prompts called at the same time, it defaults to 1.
+	// The maxConcurrency of a llm can not allow more calls in flight than this.
+	MaxConcurrentReconciles int
 }
 
 //+kubebuilder:rbac:groups=base.fleezesd.io,resources=prompts,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=base.fleezesd.io,resources=prompts/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=base.fleezesd.io,resources=prompts/finalizers,verbs=update
+//+kubebuilder:rbac:groups=base.fleezesd.io,resources=llms,verbs=get;list;watch
+//+kubebuilder:rbac:groups=base.fleezesd.io,resources=prompttemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=base.fleezesd.io,resources=referencegrants,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=base.fleezesd.io,resources=llms/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=base.fleezesd.io,resources=workers,verbs=get;list;watch;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -94,6 +113,16 @@ func (r *PromptReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	}
 
 	err := r.CallLLM(ctx, logger, prompt)
+	var throttled *llms.Throttled
+	if errors.As(err, &throttled) {
+		logger.Info("Prompt is throttled", "reason", throttled.Error())
+		return ctrl.Result{RequeueAfter: throttled.RetryAfter}, nil
+	}
+	var waking *workerWaking
+	if errors.As(err, &waking) {
+		logger.Info("Waiting for worker to wake up", "worker", waking.worker)
+		return ctrl.Result{RequeueAfter: workerWakeUpInterval}, nil
+	}
 	if err != nil {
 		logger.Error(err, "Failed to call llm")
 		return reconcile.Result{}, err
@@ -107,14 +136,17 @@ func (r *PromptReconciler) CallLLM(ctx context.Context, logger logr.Logger, prom
 		return err
 	}
 	llm, err := r.getLLMConfig(ctx, prompt)
+	if errors.Is(err, basev1alpha1.ErrReferenceNotGranted) {
+		// the prompt is picked up again once a reference grant allows the reference
+		return r.updateStatus(ctx, prompt, nil, err)
+	}
 	if err != nil {
 		return err
 	}
-	apiKey, err := llm.AuthAPIKey(ctx, r.Client)
-	if err != nil {
-		return r.UpdateStatus(ctx, prompt, nil, err)
+	if llm.Spec.Provider.GetType() == basev1alpha1.ProviderTypeGroup {
+		return r.callGroup(ctx, logger, prompt, llm)
 	}
-	return r.executeLLMCall(ctx, apiKey, prompt, llm)
+	return r.executeLLMCall(ctx, prompt, llm)
 }
 
 func (r *PromptReconciler) validatePrompt(prompt *basev1alpha1.Prompt) error {
@@ -124,39 +156,375 @@ func (r *PromptReconciler) validatePrompt(prompt *basev1alpha1.Prompt) error {
 	return nil
 }
 
+// getLLMConfig gets the llm of prompt, a llm of another namespace must be granted to the prompt
 func (r *PromptReconciler) getLLMConfig(ctx context.Context, prompt *basev1alpha1.Prompt) (*basev1alpha1.LLM, error) {
+	namespace := lo.FromPtrOr(prompt.Spec.LLM.Namespace, prompt.Namespace)
+	if err := basev1alpha1.CheckReference(ctx, r.Client,
+		basev1alpha1.ReferenceGrantFrom{Kind: basev1alpha1.KindPrompt, Namespace: prompt.Namespace},
+		namespace, basev1alpha1.ReferenceGrantTo{Kind: basev1alpha1.KindLLM, Name: prompt.Spec.LLM.Name}); err != nil {
+		return nil, err
+	}
 	llm := &basev1alpha1.LLM{}
 	err := r.Get(ctx, types.NamespacedName{
 		Name:      prompt.Spec.LLM.Name,
-		Namespace: prompt.Namespace,
+		Namespace: namespace,
 	}, llm)
 	return llm, err
 }
 
-func (r *PromptReconciler) executeLLMCall(ctx context.Context, apiKey string, prompt *basev1alpha1.Prompt, llm *basev1alpha1.LLM) error {
+func (r *PromptReconciler) executeLLMCall(ctx context.Context, prompt *basev1alpha1.Prompt, llm *basev1alpha1.LLM) error {
+	prompt.Status.Backend = ""
+	messages, err := r.renderMessages(ctx, prompt)
+	if err != nil {
+		return r.UpdateStatus(ctx, prompt, nil, err)
+	}
+	err = r.wakeUpWorker(ctx, llm)
+	var waking *workerWaking
+	if errors.As(err, &waking) {
+		return r.wait(ctx, prompt, basev1alpha1.ReasonPending, err)
+	}
+	if err != nil {
+		return r.UpdateStatus(ctx, prompt, nil, err)
+	}
+	call, err := r.prepareCall(ctx, prompt, llm, "", messages)
+	var unavailable *modelUnavailableError
+	if errors.As(err, &unavailable) && !unavailable.unhealthy {
+		// the prompt can not succeed until it is changed, so it is not requeued
+		return r.updateStatus(ctx, prompt, nil, err)
+	}
+	if err != nil {
+		return r.UpdateStatus(ctx, prompt, nil, err)
+	}
+	resp, err := r.doCall(ctx, prompt, call, llm.GetRetryPolicy())
+	var throttled *llms.Throttled
+	if errors.As(err, &throttled) {
+		return r.throttle(ctx, prompt, err)
+	}
+	return r.finishCall(ctx, prompt, call, resp, err)
+}
+
+// callGroup sends the prompt to the first healthy backend of group
+// and fails over to the next one if the call fails with a retryable error
+func (r *PromptReconciler) callGroup(ctx context.Context, logger logr.Logger, prompt *basev1alpha1.Prompt, group *basev1alpha1.LLM) error {
+	messages, err := r.renderMessages(ctx, prompt)
+	if err != nil {
+		return r.UpdateStatus(ctx, prompt, nil, err)
+	}
 	var (
-		callData  []byte
-		llmClient llms.LLM
-		err       error
+		errs      []error
+		throttled *llms.Throttled
+		groupType llms.LLMType
 	)
-	switch llm.Spec.Type {
-	case llms.OpenAI:
-		llmClient, err = openai.NewOpenAI(apiKey, llm.Spec.Endpoint.URL)
+	allThrottled := true
+	for _, backend := range group.Spec.Group.OrderedBackends() {
+		llm := &basev1alpha1.LLM{}
+		if err := r.Get(ctx, types.NamespacedName{Name: backend.Name, Namespace: group.Namespace}, llm); err != nil {
+			errs, allThrottled = append(errs, fmt.Errorf("backend %s: %w", backend.Name, err)), false
+			continue
+		}
+		if llm.Spec.Provider.GetType() == basev1alpha1.ProviderTypeGroup || !llm.IsReady() {
+			errs, allThrottled = append(errs, fmt.Errorf("backend %s: not ready", backend.Name)), false
+			continue
+		}
+		// the params of the prompt are resolved for one llm type, a backend of another type would get none
+		if groupType == "" {
+			groupType = llm.Spec.Type
+		} else if llm.Spec.Type != groupType {
+			errs, allThrottled = append(errs, fmt.Errorf("backend %s: type %s differs from type %s of the other backends", backend.Name, llm.Spec.Type, groupType)), false
+			continue
+		}
+		call, err := r.prepareCall(ctx, prompt, llm, backend.Model, messages)
 		if err != nil {
+			errs, allThrottled = append(errs, fmt.Errorf("backend %s: %w", backend.Name, err)), false
+			continue
+		}
+		prompt.Status.Backend = llm.Name
+		// failing over to the next backend takes the place of retries
+		resp, err := r.doCall(ctx, prompt, call, llms.RetryPolicy{})
+		var backendThrottled *llms.Throttled
+		if errors.As(err, &backendThrottled) {
+			if throttled == nil || backendThrottled.RetryAfter < throttled.RetryAfter {
+				throttled = backendThrottled
+			}
+		} else if !llms.IsRetryable(err) {
+			return r.finishCall(ctx, prompt, call, resp, err)
+		} else {
+			allThrottled = false
+		}
+		logger.Info("Failing over to the next backend", "backend", llm.Name, "reason", err.Error())
+		errs = append(errs, fmt.Errorf("backend %s: %w", backend.Name, err))
+	}
+
+	prompt.Status.Backend = ""
+	if throttled != nil && allThrottled {
+		return r.throttle(ctx, prompt, throttled)
+	}
+	return r.UpdateStatus(ctx, prompt, nil, fmt.Errorf("no backend of llm group %s could serve the prompt: %w", group.Name, errors.Join(errs...)))
+}
+
+// workerWakeUpInterval is the wait time before a prompt waiting for a worker to wake up is tried again
+const workerWakeUpInterval = 30 * time.Second
+
+// workerWaking is returned while the worker of a llm is woken up from zero replicas
+type workerWaking struct {
+	worker string
+}
+
+func (e *workerWaking) Error() string {
+	return fmt.Sprintf("worker %s is scaled to zero, waiting for it to wake up", e.worker)
+}
+
+// wakeUpWorker wakes up the worker of llm if its autoscaler scaled it to zero.
+// A *workerWaking error is returned until the worker is ready, other errors of the worker are left to the call.
+func (r *PromptReconciler) wakeUpWorker(ctx context.Context, llm *basev1alpha1.LLM) error {
+	if llm.Spec.Provider.GetType() != basev1alpha1.ProviderTypeWorker || llm.IsReady() {
+		return nil
+	}
+	key := llmWorkerKey(llm)
+	if err := basev1alpha1.CheckReference(ctx, r.Client,
+		basev1alpha1.ReferenceGrantFrom{Kind: basev1alpha1.KindLLM, Namespace: llm.Namespace},
+		key.Namespace, basev1alpha1.ReferenceGrantTo{Kind: basev1alpha1.KindWorker, Name: key.Name}); err != nil {
+		return nil
+	}
+	w := &basev1alpha1.Worker{}
+	if err := r.Get(ctx, key, w); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if lo.IsNil(w.Spec.Autoscaling) || w.Spec.Autoscaling.GetMinReplicas() > 0 {
+		return nil
+	}
+	switch w.Status.GetCondition(basev1alpha1.TypeReady).Reason {
+	case basev1alpha1.ReasonOffline:
+		base := w.DeepCopy()
+		w.Annotations = lo.Assign(w.Annotations, map[string]string{worker.AnnotationWakeUp: time.Now().UTC().Format(time.RFC3339)})
+		if err := r.Patch(ctx, w, client.MergeFrom(base)); err != nil {
 			return err
 		}
-		callData = prompt.Spec.OpenAIParams.Marshal()
+	case basev1alpha1.ReasonPending:
+		// woken up by an earlier prompt
 	default:
-		return errors.New("unsupported LLM type")
+		return nil
+	}
+	return &workerWaking{worker: key.String()}
+}
+
+// modelUnavailableError is returned when the model of a prompt is not served by the llm,
+// or failed the last health check of the llm
+type modelUnavailableError struct {
+	model     string
+	llm       string
+	unhealthy bool
+}
+
+func (e *modelUnavailableError) Error() string {
+	if e.unhealthy {
+		return fmt.Sprintf("model %s is unhealthy on llm %s, it failed the last health check", e.model, e.llm)
+	}
+	return fmt.Sprintf("model %s is not available on llm %s", e.model, e.llm)
+}
+
+// llmCall is a prompt call prepared for a llm
+type llmCall struct {
+	llm      *basev1alpha1.LLM
+	client   llms.LLM
+	params   llms.ModelParams
+	options  []langchainllms.CallOption
+	streamer *promptStreamer
+	// responseSchema is the schema sent with the call, which the output is validated against
+	responseSchema []byte
+}
+
+// renderMessages renders the template of prompt, if it has one, and records the hash of the messages
+func (r *PromptReconciler) renderMessages(ctx context.Context, prompt *basev1alpha1.Prompt) ([]llms.Message, error) {
+	prompt.Status.RenderedHash = ""
+	if prompt.Spec.Template == nil {
+		return nil, nil
+	}
+	messages, err := r.renderTemplate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	prompt.Status.RenderedHash = basev1alpha1.RenderedHash(messages)
+	return messages, nil
+}
+
+// prepareCall builds the client and params of a call of prompt to llm.
+// model replaces the model of the prompt params if it is set.
+func (r *PromptReconciler) prepareCall(ctx context.Context, prompt *basev1alpha1.Prompt, llm *basev1alpha1.LLM,
+	model string, messages []llms.Message) (*llmCall, error) {
+	opts, err := llm.ClientOptions(ctx, r.Client)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := llms.GetProvider(llm.Spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	llmClient, err := provider.New(opts)
+	if err != nil {
+		return nil, err
+	}
+	params := provider.NewParams()
+	if raw := prompt.Spec.RawModelParams(llm.Spec.Type); len(raw) > 0 {
+		if err := params.Unmarshal(raw); err != nil {
+			return nil, err
+		}
+	}
+	if model != "" {
+		raw, err := json.Marshal(map[string]string{"model": model})
+		if err != nil {
+			return nil, err
+		}
+		if err := params.Unmarshal(raw); err != nil {
+			return nil, err
+		}
 	}
-	resp, err := llmClient.Call(callData)
+	if model := params.GetModel(); !llm.IsModelAvailable(model) {
+		return nil, &modelUnavailableError{model: model, llm: llm.Name, unhealthy: lo.Contains(llm.Status.UnavailableModels(), model)}
+	}
+	if len(messages) > 0 {
+		if err := params.PrependMessages(messages...); err != nil {
+			return nil, err
+		}
+	}
+
+	call := &llmCall{llm: llm, client: llmClient, params: params}
+	if prompt.Spec.ResponseSchema != nil {
+		call.responseSchema = prompt.Spec.ResponseSchema.Raw
+	}
+	if structured, ok := params.(llms.StructuredOutputParams); ok {
+		if call.responseSchema != nil {
+			structured.SetResponseSchema(call.responseSchema)
+		}
+		// the params may carry a schema of their own, the output has to match the one sent
+		call.responseSchema = structured.GetResponseSchema()
+	}
+	if tools := prompt.Spec.LLMTools(); len(tools) > 0 {
+		call.options = append(call.options, llms.WithTools(tools))
+	}
+	if params.IsIncremental() {
+		call.streamer = &promptStreamer{client: r.Client, prompt: prompt}
+		call.options = append(call.options, langchainllms.WithStreamingFunc(call.streamer.onChunk))
+	}
+	return call, nil
+}
+
+// doCall sends a prepared call within the rate limits of its llm, retrying it with policy.
+// A *llms.Throttled error is returned if the rate limits do not allow the call.
+func (r *PromptReconciler) doCall(ctx context.Context, prompt *basev1alpha1.Prompt, call *llmCall, policy llms.RetryPolicy) (llms.Response, error) {
+	prompt.Status.Model, prompt.Status.Usage, prompt.Status.ToolCalls = call.params.GetModel(), nil, nil
+	prompt.Status.Result, prompt.Status.Retries = nil, 0
+	var resp llms.Response
+	retries, err := policy.Do(ctx, func() error {
+		// every attempt counts against the rate limits, a throttled retry ends the call
+		release := func(llms.Usage) {}
+		if r.Limiters != nil {
+			var err error
+			if release, err = r.Limiters.Acquire(client.ObjectKeyFromObject(call.llm).String(), call.llm.GetRateLimits()); err != nil {
+				return err
+			}
+		}
+		if call.streamer != nil {
+			call.streamer.reset()
+		}
+		var callErr error
+		resp, callErr = call.client.Call(ctx, call.params.Marshal(), call.options...)
+		if resp != nil {
+			release(resp.TokenUsage())
+		} else {
+			release(llms.Usage{})
+		}
+		return callErr
+	})
+	prompt.Status.Retries = int32(retries)
+	return resp, err
+}
+
+// finishCall records the result of call in prompt status
+func (r *PromptReconciler) finishCall(ctx context.Context, prompt *basev1alpha1.Prompt, call *llmCall, resp llms.Response, err error) error {
 	if err != nil {
-		return r.UpdateStatus(ctx, prompt, resp, err)
+		// retryable errors are retried by the policy already, so the prompt is not requeued
+		return r.updateStatus(ctx, prompt, resp, err)
+	}
+	if usage := resp.TokenUsage(); usage.TotalTokens > 0 {
+		tokenUsage := basev1alpha1.NewTokenUsage(usage)
+		prompt.Status.Usage = &tokenUsage
+		if err := r.recordLLMUsage(ctx, call.llm, prompt.Status.Model, tokenUsage); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to record llm usage", "llm", call.llm.Name)
+		}
+	}
+	// the schema applies to the generated content, not to requested tool calls
+	if call.responseSchema != nil && len(resp.GetToolCalls()) == 0 {
+		result, err := llms.ParseResponse(call.responseSchema, resp.GetData())
+		if err != nil {
+			// the call itself succeeded and a retry would only repeat its cost, so it is not requeued
+			return r.updateStatus(ctx, prompt, resp, err)
+		}
+		prompt.Status.Result = &runtime.RawExtension{Raw: result}
 	}
 	return r.UpdateStatus(ctx, prompt, resp, nil)
 }
 
+// throttle marks the prompt as throttled by the rate limits of its llm.
+// The prompt is requeued once the limit allows a call again.
+func (r *PromptReconciler) throttle(ctx context.Context, prompt *basev1alpha1.Prompt, err error) error {
+	return r.wait(ctx, prompt, basev1alpha1.ReasonThrottled, err)
+}
+
+// wait marks the prompt as waiting for reason and returns err, which tells when the prompt is requeued
+func (r *PromptReconciler) wait(ctx context.Context, prompt *basev1alpha1.Prompt, reason basev1alpha1.ConditionReason, err error) error {
+	promptDeepCopy := prompt.DeepCopy()
+	promptDeepCopy.Status.SetConditions(basev1alpha1.Condition{
+		Type:               basev1alpha1.TypeDone,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            err.Error(),
+	})
+	if updateErr := r.Client.Status().Update(ctx, promptDeepCopy); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// renderTemplate renders the template referenced by prompt with its variables
+func (r *PromptReconciler) renderTemplate(ctx context.Context, prompt *basev1alpha1.Prompt) ([]llms.Message, error) {
+	ref := prompt.Spec.Template
+	tmpl := &basev1alpha1.PromptTemplate{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: prompt.Namespace}, tmpl); err != nil {
+		return nil, err
+	}
+	variables, err := ref.ResolveVariables(ctx, prompt.Namespace, r.Client)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Render(variables)
+}
+
+// recordLLMUsage adds the usage of a prompt call to the cumulative usage in llm status.
+// Only status.usage is patched, so that the conditions written by the probe of the llm are left alone.
+// The patch is rejected if the llm changed since it was read, concurrent calls would lose their usage otherwise.
+func (r *PromptReconciler) recordLLMUsage(ctx context.Context, llm *basev1alpha1.LLM, model string, usage basev1alpha1.TokenUsage) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &basev1alpha1.LLM{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(llm), latest); err != nil {
+			return err
+		}
+		base := latest.DeepCopy()
+		if err := latest.RecordUsage(model, usage); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to estimate llm usage cost", "llm", llm.Name)
+		}
+		return r.Status().Patch(ctx, latest, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+	})
+}
+
 func (r *PromptReconciler) UpdateStatus(ctx context.Context, prompt *basev1alpha1.Prompt,
+	response llms.Response, err error) error {
+	return errors.Join(err, r.updateStatus(ctx, prompt, response, err))
+}
+
+// updateStatus records the call result in prompt status and returns only the error of the update
+func (r *PromptReconciler) updateStatus(ctx context.Context, prompt *basev1alpha1.Prompt,
 	response llms.Response, err error) error {
 	promptDeepCopy := prompt.DeepCopy()
 	newCond := basev1alpha1.Condition{
@@ -171,11 +539,64 @@ func (r *PromptReconciler) UpdateStatus(ctx context.Context, prompt *basev1alpha
 		newCond.Reason = basev1alpha1.ReasonReconcileError
 		newCond.Message = err.Error()
 	}
-	promptDeepCopy.Status.SetConditions(newCond)
+	conditions := []basev1alpha1.Condition{newCond}
+	if progressing := prompt.Status.GetCondition(basev1alpha1.TypeProgressing); progressing.Status == corev1.ConditionTrue {
+		conditions = append(conditions, basev1alpha1.Condition{
+			Type:               basev1alpha1.TypeProgressing,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             newCond.Reason,
+			Message:            progressing.Message,
+		})
+	}
+	promptDeepCopy.Status.SetConditions(conditions...)
+	promptDeepCopy.Status.Partial = ""
 	if response != nil {
 		promptDeepCopy.Status.Data = response.Bytes()
+		promptDeepCopy.Status.ToolCalls = response.GetToolCalls()
+	}
+	return r.Client.Status().Update(ctx, promptDeepCopy)
+}
+
+// promptStreamer collects streamed chunks and periodically patches the partial
+// output into prompt status, so long generations are visible before they finish.
+type promptStreamer struct {
+	client client.Client
+	prompt *basev1alpha1.Prompt
+
+	partial    strings.Builder
+	chunks     int
+	lastUpdate time.Time
+}
+
+// reset drops the output of a failed attempt before it is retried
+func (s *promptStreamer) reset() {
+	s.partial.Reset()
+	s.chunks = 0
+}
+
+func (s *promptStreamer) onChunk(ctx context.Context, chunk []byte) error {
+	s.partial.Write(chunk)
+	s.chunks++
+	if time.Since(s.lastUpdate) < streamStatusInterval {
+		return nil
 	}
-	return errors.Join(err, r.Client.Status().Update(ctx, promptDeepCopy))
+	s.lastUpdate = time.Now()
+
+	base := s.prompt.DeepCopy()
+	s.prompt.Status.Partial = s.partial.String()
+	s.prompt.Status.SetConditions(basev1alpha1.Condition{
+		Type:               basev1alpha1.TypeProgressing,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             basev1alpha1.ReasonStreaming,
+		Message:            fmt.Sprintf("received %d chunks", s.chunks),
+	})
+	// a failed progress update must not abort the generation itself
+	if err := s.client.Status().Patch(ctx, s.prompt, client.MergeFrom(base)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to patch partial prompt output")
+	}
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -184,9 +605,64 @@ func (r *PromptReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&basev1alpha1.Prompt{}, builder.WithPredicates(
 			PromptPredicates{},
 		)). // predicate for prompt crd
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		// retry failed prompts once the credentials of their llm are rotated
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.failedPromptsForSecret),
+			builder.OnlyMetadata, builder.WithPredicates(secretChanged)).
+		// retry failed prompts of other namespaces once a grant allows them to call the llms
+		Watches(&basev1alpha1.ReferenceGrant{}, handler.EnqueueRequestsFromMapFunc(r.failedPromptsForGrant)).
 		Complete(r)
 }
 
+// failedPromptsForGrant returns the prompts which did not succeed with a llm of the namespace of grant
+// and are allowed to call it by grant
+func (r *PromptReconciler) failedPromptsForGrant(ctx context.Context, o client.Object) []reconcile.Request {
+	grant := o.(*basev1alpha1.ReferenceGrant)
+	var requests []reconcile.Request
+	for _, from := range grant.Spec.From {
+		if from.Kind != basev1alpha1.KindPrompt || from.Namespace == grant.Namespace {
+			continue
+		}
+		list := &basev1alpha1.PromptList{}
+		if err := r.List(ctx, list, client.InNamespace(from.Namespace)); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list prompts of grant", "grant", client.ObjectKeyFromObject(grant))
+			continue
+		}
+		for i := range list.Items {
+			prompt := &list.Items[i]
+			if lo.IsNil(prompt.Spec.LLM) || lo.FromPtr(prompt.Spec.LLM.Namespace) != grant.Namespace {
+				continue
+			}
+			if done := prompt.Status.GetCondition(basev1alpha1.TypeDone); done.Status == corev1.ConditionFalse {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(prompt)})
+			}
+		}
+	}
+	return requests
+}
+
+// failedPromptsForSecret returns the prompts which did not succeed with a llm authenticating with secret
+func (r *PromptReconciler) failedPromptsForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	llmList := llmsForSecret(ctx, r.Client, secret)
+	for i := range llmList {
+		llmList = append(llmList, groupsForLLM(ctx, r.Client, &llmList[i])...)
+	}
+	for _, llm := range llmList {
+		list := &basev1alpha1.PromptList{}
+		if err := r.List(ctx, list, client.MatchingFields{promptLLMIndex: client.ObjectKeyFromObject(&llm).String()}); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list prompts of llm", "llm", client.ObjectKeyFromObject(&llm))
+			continue
+		}
+		for i := range list.Items {
+			if done := list.Items[i].Status.GetCondition(basev1alpha1.TypeDone); done.Status == corev1.ConditionFalse {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+			}
+		}
+	}
+	return requests
+}
+
 type PromptPredicates struct {
 	predicate.Funcs
 }
@@ -194,8 +670,9 @@ type PromptPredicates struct {
 // set create predicates
 func (p PromptPredicates) Create(ce event.CreateEvent) bool {
 	prompt := ce.Object.(*basev1alpha1.Prompt)
-	// check new prompt
-	return len(prompt.Status.ConditionedStatus.Conditions) == 0
+	// check new prompt, throttled prompts are picked up again as well after a restart
+	return len(prompt.Status.ConditionedStatus.Conditions) == 0 ||
+		prompt.Status.GetCondition(basev1alpha1.TypeDone).Reason == basev1alpha1.ReasonThrottled
 }
 
 func (p PromptPredicates) Update(ue event.UpdateEvent) bool {
@@ -18,45 +18,485 @@ package base
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
+	"github.com/fleezesd/llm-operator/pkg/worker"
+	"github.com/go-logr/logr"
+	"github.com/samber/lo"
 )
 
 // WorkerReconciler reconciles a Worker object
 type WorkerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Autoscaler decides the replicas of workers with autoscaling, spec.replicas is used if it is nil
+	Autoscaler *worker.Autoscaler
 }
 
+// autoscaleInterval is the time between two scrapes of the load of an autoscaled worker
+const autoscaleInterval = 30 * time.Second
+
 //+kubebuilder:rbac:groups=base.fleezesd.io,resources=workers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=base.fleezesd.io,resources=workers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=base.fleezesd.io,resources=workers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=base.fleezesd.io,resources=llms,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the Worker object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
+// It renders the deployment, service and storage of a worker and mirrors the state of its pods.
+// The rendered objects are owned by the worker and garbage collected with it.
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
 func (r *WorkerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	logger.Info("Reconciling worker resource")
 
-	// TODO(user): your logic here
+	w := &basev1alpha1.Worker{}
+	if err := r.Get(ctx, req.NamespacedName, w); err != nil {
+		logger.V(1).Info("Failed to get Worker")
+		if apierrors.IsNotFound(err) && r.Autoscaler != nil {
+			r.Autoscaler.Forget(req.String())
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if w.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
 
+	if err := r.reconcileWorker(ctx, logger, w); err != nil {
+		logger.Error(err, "Failed to reconcile worker")
+		return ctrl.Result{}, errors.Join(err, r.setCondition(ctx, w, nil, w.ErrorCondition(err.Error())))
+	}
+	if r.autoscaled(w) {
+		return ctrl.Result{RequeueAfter: autoscaleInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// reconcileWorker renders the objects of w and updates its status from them
+func (r *WorkerReconciler) reconcileWorker(ctx context.Context, logger logr.Logger, w *basev1alpha1.Worker) error {
+	model, err := r.getModel(ctx, w)
+	if err != nil {
+		return err
+	}
+	ds, err := r.getDataSource(ctx, w, model)
+	if err != nil {
+		return err
+	}
+	if err := r.reconcilePVC(ctx, w); err != nil {
+		return fmt.Errorf("reconcile storage: %w", err)
+	}
+	replicas, err := r.replicas(ctx, logger, w)
+	if err != nil {
+		return fmt.Errorf("autoscale: %w", err)
+	}
+	deploy, err := r.reconcileDeployment(ctx, w, model, ds, replicas)
+	if err != nil {
+		return fmt.Errorf("reconcile deployment: %w", err)
+	}
+	if err := r.reconcileService(ctx, w); err != nil {
+		return fmt.Errorf("reconcile service: %w", err)
+	}
+
+	pod, err := r.latestPod(ctx, w)
+	if err != nil {
+		return err
+	}
+	cond := workerCondition(w, deploy, pod)
+	loaded := loadedCondition(w, model, deploy, pod)
+	logger.V(1).Info("Worker state", "reason", cond.Reason, "message", cond.Message, "loaded", loaded.Reason)
+	if err := r.reconcileLLM(ctx, w, model, cond.Status == corev1.ConditionTrue); err != nil {
+		return fmt.Errorf("reconcile llm: %w", err)
+	}
+	return r.setCondition(ctx, w, pod, cond, loaded)
+}
+
+// getModel returns the model served by w, a model of another namespace must be granted to it
+func (r *WorkerReconciler) getModel(ctx context.Context, w *basev1alpha1.Worker) (*basev1alpha1.Model, error) {
+	if lo.IsNil(w.Spec.Model) {
+		return nil, errors.New("no model configured")
+	}
+	key := w.ModelKey()
+	if err := basev1alpha1.CheckReference(ctx, r.Client,
+		basev1alpha1.ReferenceGrantFrom{Kind: basev1alpha1.KindWorker, Namespace: w.Namespace},
+		key.Namespace, basev1alpha1.ReferenceGrantTo{Kind: basev1alpha1.KindModel, Name: key.Name}); err != nil {
+		return nil, err
+	}
+	model := &basev1alpha1.Model{}
+	if err := r.Get(ctx, key, model); err != nil {
+		return nil, fmt.Errorf("get model %s: %w", key, err)
+	}
+	return model, nil
+}
+
+// getDataSource returns the datasource holding the files of model, or nil if the model has no source.
+// A datasource of another namespace must be granted to the model.
+func (r *WorkerReconciler) getDataSource(ctx context.Context, w *basev1alpha1.Worker, model *basev1alpha1.Model) (*basev1alpha1.DataSource, error) {
+	if lo.IsNil(model.Spec.Source) {
+		return nil, nil
+	}
+	key := model.SourceKey()
+	if err := basev1alpha1.CheckReference(ctx, r.Client,
+		basev1alpha1.ReferenceGrantFrom{Kind: basev1alpha1.KindModel, Namespace: model.Namespace},
+		key.Namespace, basev1alpha1.ReferenceGrantTo{Kind: basev1alpha1.KindDataSource, Name: key.Name}); err != nil {
+		return nil, err
+	}
+	ds := &basev1alpha1.DataSource{}
+	if err := r.Get(ctx, key, ds); err != nil {
+		return nil, fmt.Errorf("get datasource %s: %w", key, err)
+	}
+	// the loader reads the auth secret as env, pods can only refer to secrets of their own namespace
+	if ds.Namespace != w.Namespace && !lo.IsNil(ds.Spec.Endpoint.AuthSecret) {
+		return nil, fmt.Errorf("datasource %s authenticates with a secret of another namespace than worker %s", key, w.Name)
+	}
+	return ds, nil
+}
+
+// autoscaled reports whether the replicas of w are decided by the autoscaler.
+// The webhook rejects autoscaling for types which report no load, spec.replicas is used in case it is bypassed.
+func (r *WorkerReconciler) autoscaled(w *basev1alpha1.Worker) bool {
+	return r.Autoscaler != nil && !lo.IsNil(w.Spec.Autoscaling) && worker.RunnerFor(w.Spec.Type).Metrics() != nil
+}
+
+// replicas returns the replicas of w, which are decided by the autoscaler from the load of its pods if w is autoscaled.
+// The time of the scaling is recorded in the status of w.
+func (r *WorkerReconciler) replicas(ctx context.Context, logger logr.Logger, w *basev1alpha1.Worker) (int32, error) {
+	// the webhook rejects more replicas than the storage allows, they are capped in case it is bypassed
+	maxReplicas := lo.Ternary(w.SharedStorage(), int32(math.MaxInt32), 1)
+	if !r.autoscaled(w) {
+		return min(lo.FromPtrOr(w.Spec.Replicas, 1), maxReplicas), nil
+	}
+	// the deployment has the name of the worker
+	deploy := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(w), deploy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return max(w.Spec.Autoscaling.GetMinReplicas(), 1), nil
+		}
+		return 0, err
+	}
+	current := lo.FromPtrOr(deploy.Spec.Replicas, 1)
+	pods, err := r.pods(ctx, w)
+	if err != nil {
+		return 0, err
+	}
+	replicas, load, err := r.Autoscaler.Scale(ctx, client.ObjectKeyFromObject(w).String(), w, pods, current)
+	if err != nil {
+		return 0, err
+	}
+	replicas = min(replicas, maxReplicas)
+	logger.V(1).Info("Worker load", "inFlight", load.InFlight, "tokensPerSecond", load.TokensPerSecond, "replicas", replicas)
+	if replicas == current {
+		return replicas, nil
+	}
+	logger.Info("Scaling worker", "from", current, "to", replicas)
+	base := w.DeepCopy()
+	w.Status.LastScaleTime = lo.ToPtr(metav1.Now())
+	return replicas, r.Status().Patch(ctx, w, client.MergeFrom(base))
+}
+
+// reconcilePVC creates the claim of the model storage, its spec is not updated afterwards
+func (r *WorkerReconciler) reconcilePVC(ctx context.Context, w *basev1alpha1.Worker) error {
+	desired := worker.PersistentVolumeClaim(w)
+	if desired == nil {
+		return nil
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+	_, err := ctrlutil.CreateOrUpdate(ctx, r.Client, pvc, func() error {
+		if pvc.CreationTimestamp.IsZero() {
+			pvc.Spec = desired.Spec
+		}
+		pvc.Labels = lo.Assign(pvc.Labels, desired.Labels)
+		return ctrlutil.SetControllerReference(w, pvc, r.Scheme)
+	})
+	return err
+}
+
+// reconcileDeployment creates or updates the deployment of w.
+// The pod template is only replaced when the rendered one changed, so that the defaults filled by the api server are kept.
+func (r *WorkerReconciler) reconcileDeployment(ctx context.Context, w *basev1alpha1.Worker, model *basev1alpha1.Model,
+	ds *basev1alpha1.DataSource, replicas int32) (*appsv1.Deployment, error) {
+	desired, err := worker.Deployment(w, model, ds)
+	if err != nil {
+		return nil, err
+	}
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+	_, err = ctrlutil.CreateOrUpdate(ctx, r.Client, deploy, func() error {
+		if deploy.CreationTimestamp.IsZero() || deploy.Annotations[worker.AnnotationTemplateHash] != desired.Annotations[worker.AnnotationTemplateHash] {
+			deploy.Spec.Selector = desired.Spec.Selector
+			deploy.Spec.Strategy = desired.Spec.Strategy
+			deploy.Spec.Template = desired.Spec.Template
+		}
+		deploy.Spec.Replicas = lo.ToPtr(replicas)
+		deploy.Labels = lo.Assign(deploy.Labels, desired.Labels)
+		deploy.Annotations = lo.Assign(deploy.Annotations, desired.Annotations)
+		return ctrlutil.SetControllerReference(w, deploy, r.Scheme)
+	})
+	return deploy, err
+}
+
+// reconcileService creates or updates the service exposing the runner of w
+func (r *WorkerReconciler) reconcileService(ctx context.Context, w *basev1alpha1.Worker) error {
+	desired := worker.Service(w)
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+	_, err := ctrlutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
+		svc.Spec.Type = desired.Spec.Type
+		svc.Spec.Selector = desired.Spec.Selector
+		svc.Spec.Ports = desired.Spec.Ports
+		svc.Labels = lo.Assign(svc.Labels, desired.Labels)
+		return ctrlutil.SetControllerReference(w, svc, r.Scheme)
+	})
+	return err
+}
+
+// reconcileLLM creates the llm calling the model of w once w is ready, and keeps it in sync afterwards.
+// The llm is owned by w and garbage collected with it. It is deleted if the model is no llm model anymore
+// or the runner of w serves no openai compatible api, the llm could never become ready then.
+func (r *WorkerReconciler) reconcileLLM(ctx context.Context, w *basev1alpha1.Worker, model *basev1alpha1.Model, ready bool) error {
+	desired := worker.LLM(w, model)
+	serves := desired != nil && model.IsLLMModel()
+	llm := &basev1alpha1.LLM{ObjectMeta: metav1.ObjectMeta{Name: w.Name, Namespace: w.Namespace}}
+	err := r.Get(ctx, client.ObjectKeyFromObject(llm), llm)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	exists := err == nil
+	switch {
+	case exists && !metav1.IsControlledBy(llm, w):
+		// never take over a llm written by hand
+		if !serves {
+			return nil
+		}
+		return fmt.Errorf("llm %s already exists and is not owned by the worker", llm.Name)
+	case !serves:
+		if exists {
+			return client.IgnoreNotFound(r.Delete(ctx, llm))
+		}
+		return nil
+	case !exists && !ready:
+		return nil
+	}
+	_, err = ctrlutil.CreateOrUpdate(ctx, r.Client, llm, func() error {
+		llm.Spec.Type = desired.Spec.Type
+		llm.Spec.Provider = desired.Spec.Provider
+		llm.Spec.Models = desired.Spec.Models
+		llm.Labels = lo.Assign(llm.Labels, desired.Labels)
+		return ctrlutil.SetControllerReference(w, llm, r.Scheme)
+	})
+	return err
+}
+
+// pods returns the pods of w
+func (r *WorkerReconciler) pods(ctx context.Context, w *basev1alpha1.Worker) ([]corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(w.Namespace), client.MatchingLabels(worker.Labels(w))); err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+	return pods.Items, nil
+}
+
+// latestPod returns the most recently created pod of w, or nil if it has none
+func (r *WorkerReconciler) latestPod(ctx context.Context, w *basev1alpha1.Worker) (*corev1.Pod, error) {
+	pods, err := r.pods(ctx, w)
+	if err != nil || len(pods) == 0 {
+		return nil, err
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp)
+	})
+	return &pods[0], nil
+}
+
+// setCondition updates the status of w with the state of pod and conditions
+func (r *WorkerReconciler) setCondition(ctx context.Context, w *basev1alpha1.Worker, pod *corev1.Pod, conditions ...basev1alpha1.Condition) error {
+	workerCopy := w.DeepCopy()
+	if pod != nil {
+		workerCopy.Status.PodStatus = pod.Status
+	}
+	workerCopy.Status.Endpoint = worker.Endpoint(w)
+	workerCopy.Status.SetConditions(conditions...)
+	if reflect.DeepEqual(workerCopy.Status, w.Status) {
+		return nil
+	}
+	return r.Client.Status().Update(ctx, workerCopy)
+}
+
+// podFailures are the waiting reasons of containers which do not recover by themselves
+var podFailures = []string{"ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CrashLoopBackOff", "CreateContainerConfigError"}
+
+// workerCondition returns the ready condition of w from its deployment and latest pod
+func workerCondition(w *basev1alpha1.Worker, deploy *appsv1.Deployment, pod *corev1.Pod) basev1alpha1.Condition {
+	replicas := lo.FromPtr(deploy.Spec.Replicas)
+	switch {
+	case replicas == 0:
+		return w.OfflineCondition()
+	case deploy.Status.ReadyReplicas > 0:
+		return w.ReadyCondition(fmt.Sprintf("%d of %d replicas ready", deploy.Status.ReadyReplicas, replicas))
+	case pod == nil:
+		return w.PendingCondition("waiting for the worker pod to be created")
+	}
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if waiting := status.State.Waiting; waiting != nil && lo.Contains(podFailures, waiting.Reason) {
+			return w.ErrorCondition(fmt.Sprintf("container %s: %s: %s", status.Name, waiting.Reason, waiting.Message))
+		}
+	}
+	if pod.Status.Phase == corev1.PodPending {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+				return w.PendingCondition(fmt.Sprintf("pod %s is not scheduled: %s", pod.Name, cond.Message))
+			}
+		}
+	}
+	return w.PendingCondition(fmt.Sprintf("waiting for pod %s to be ready", pod.Name))
+}
+
+// loadedCondition returns the loaded condition of w from the loader init container of its latest pod
+func loadedCondition(w *basev1alpha1.Worker, model *basev1alpha1.Model, deploy *appsv1.Deployment, pod *corev1.Pod) basev1alpha1.Condition {
+	source := worker.DescribeSource(model)
+	_, hasLoader := lo.Find(deploy.Spec.Template.Spec.InitContainers, func(c corev1.Container) bool { return c.Name == worker.LoaderContainer })
+	if !hasLoader {
+		return w.LoadedCondition(fmt.Sprintf("model files are expected in %s", source))
+	}
+	if pod == nil {
+		return w.LoadingCondition(fmt.Sprintf("waiting for the loader to load model files from %s", source))
+	}
+	status, ok := lo.Find(pod.Status.InitContainerStatuses, func(s corev1.ContainerStatus) bool { return s.Name == worker.LoaderContainer })
+	if !ok {
+		return w.LoadingCondition(fmt.Sprintf("waiting for the loader to load model files from %s", source))
+	}
+	if terminated := status.State.Terminated; terminated != nil {
+		if terminated.ExitCode == 0 {
+			return w.LoadedCondition(fmt.Sprintf("model files loaded from %s", source))
+		}
+		return w.LoadErrorCondition(fmt.Sprintf("load model files from %s: exit code %d: %s", source, terminated.ExitCode, terminated.Message))
+	}
+	if waiting := status.State.Waiting; waiting != nil {
+		// the loader is restarted after a failure, report the last one while it waits
+		if last := status.LastTerminationState.Terminated; last != nil && last.ExitCode != 0 {
+			return w.LoadErrorCondition(fmt.Sprintf("load model files from %s: exit code %d: %s", source, last.ExitCode, last.Message))
+		}
+		if lo.Contains(podFailures, waiting.Reason) {
+			return w.LoadErrorCondition(fmt.Sprintf("load model files from %s: %s: %s", source, waiting.Reason, waiting.Message))
+		}
+	}
+	if running := status.State.Running; running != nil {
+		return w.LoadingCondition(fmt.Sprintf("loading model files from %s since %s", source, running.StartedAt.UTC().Format(time.RFC3339)))
+	}
+	return w.LoadingCondition(fmt.Sprintf("waiting for the loader to load model files from %s", source))
+}
+
+// workerPod filters the events of pods which are not rendered for a worker
+var workerPod = predicate.NewPredicateFuncs(func(o client.Object) bool {
+	_, ok := o.GetLabels()[worker.LabelWorker]
+	return ok
+})
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *WorkerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&basev1alpha1.Worker{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		// the status of the llm changes with every probe, only changes of its spec are reverted
+		Owns(&basev1alpha1.LLM{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// mirror the state of the pods, they are owned by the replica sets of the deployment
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(
+			func(ctx context.Context, o client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetLabels()[worker.LabelWorker]}}}
+			},
+		), builder.WithPredicates(workerPod)).
+		// render the workers again once their model is created or changed
+		Watches(&basev1alpha1.Model{}, handler.EnqueueRequestsFromMapFunc(
+			func(ctx context.Context, o client.Object) []reconcile.Request {
+				return lo.Map(workersForModel(ctx, r.Client, o), func(w basev1alpha1.Worker, _ int) reconcile.Request {
+					return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&w)}
+				})
+			},
+		)).
+		// render the workers again once the datasource of their model is created or changed
+		Watches(&basev1alpha1.DataSource{}, handler.EnqueueRequestsFromMapFunc(r.requestsForDataSource)).
+		// retry workers of other namespaces once a grant allows them to use the models
+		Watches(&basev1alpha1.ReferenceGrant{}, handler.EnqueueRequestsFromMapFunc(r.requestsForGrant)).
 		Complete(r)
 }
+
+// requestsForDataSource returns the workers of the models whose files are held by the datasource
+func (r *WorkerReconciler) requestsForDataSource(ctx context.Context, o client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, model := range modelsForDataSource(ctx, r.Client, o) {
+		for _, w := range workersForModel(ctx, r.Client, &model) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&w)})
+		}
+	}
+	return requests
+}
+
+// requestsForGrant returns the workers of other namespaces which are allowed by grant to use a model of its namespace,
+// and the workers of the models which are allowed to load files from a datasource of its namespace
+func (r *WorkerReconciler) requestsForGrant(ctx context.Context, o client.Object) []reconcile.Request {
+	grant := o.(*basev1alpha1.ReferenceGrant)
+	var requests []reconcile.Request
+	for _, from := range grant.Spec.From {
+		if from.Namespace == grant.Namespace {
+			continue
+		}
+		if from.Kind == basev1alpha1.KindModel {
+			list := &basev1alpha1.ModelList{}
+			if err := r.List(ctx, list, client.InNamespace(from.Namespace)); err != nil {
+				log.FromContext(ctx).Error(err, "Failed to list models of grant", "grant", client.ObjectKeyFromObject(grant))
+				continue
+			}
+			for i := range list.Items {
+				model := &list.Items[i]
+				if lo.IsNil(model.Spec.Source) || model.SourceKey().Namespace != grant.Namespace {
+					continue
+				}
+				for _, w := range workersForModel(ctx, r.Client, model) {
+					requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&w)})
+				}
+			}
+			continue
+		}
+		if from.Kind != basev1alpha1.KindWorker {
+			continue
+		}
+		list := &basev1alpha1.WorkerList{}
+		if err := r.List(ctx, list, client.InNamespace(from.Namespace)); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list workers of grant", "grant", client.ObjectKeyFromObject(grant))
+			continue
+		}
+		for i := range list.Items {
+			w := &list.Items[i]
+			if !lo.IsNil(w.Spec.Model) && w.ModelKey().Namespace == grant.Namespace {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(w)})
+			}
+		}
+	}
+	return requests
+}
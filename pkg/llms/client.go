@@ -0,0 +1,82 @@
+package llms
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ClientOptions configure how a llm client connects to its api
+type ClientOptions struct {
+	// APIKey is sent the way the provider api expects it
+	APIKey string
+	// BaseURL of the api, the provider default is used if empty
+	BaseURL string
+	// Insecure skips the verification of the server certificate
+	Insecure bool
+	// CABundle holds pem encoded certificates trusted in addition to the system ones
+	CABundle []byte
+	// Headers are sent with every request, unless the client sets them itself
+	Headers map[string]string
+	// AuthHeaders carry credentials other than APIKey, they override the headers set by the client
+	AuthHeaders map[string]string
+	// Certificates are presented to servers requiring client certificates
+	Certificates []tls.Certificate
+}
+
+// HasCredentials reports whether the options carry any way to authenticate
+func (o ClientOptions) HasCredentials() bool {
+	return o.APIKey != "" || len(o.AuthHeaders) > 0 || len(o.Certificates) > 0
+}
+
+// HTTPClient returns a http client which honours the tls settings and headers of the options
+func (o ClientOptions) HTTPClient(timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.Insecure || len(o.CABundle) > 0 || len(o.Certificates) > 0 {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: o.Insecure,
+			Certificates:       o.Certificates,
+		}
+		if len(o.CABundle) > 0 {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(o.CABundle) {
+				return nil, errors.New("no valid certificate found in ca bundle")
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if len(o.Headers) > 0 || len(o.AuthHeaders) > 0 {
+		roundTripper = &headerTransport{headers: o.Headers, authHeaders: o.AuthHeaders, next: transport}
+	}
+	return &http.Client{Timeout: timeout, Transport: roundTripper}, nil
+}
+
+// headerTransport adds headers to the requests which do not have them yet
+// and sets the auth headers on all of them
+type headerTransport struct {
+	headers     map[string]string
+	authHeaders map[string]string
+	next        http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		if req.Header.Get(k) == "" {
+			req.Header.Set(k, v)
+		}
+	}
+	for k, v := range t.authHeaders {
+		req.Header.Set(k, v)
+	}
+	return t.next.RoundTrip(req)
+}
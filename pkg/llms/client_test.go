@@ -0,0 +1,43 @@
+package llms
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHeaderTransport(t *testing.T) {
+	var got http.Header
+	transport := &headerTransport{
+		headers:     map[string]string{"X-Project": "default", "Anthropic-Version": "other"},
+		authHeaders: map[string]string{"Authorization": "Bearer token"},
+		next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			got = req.Header
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+	}
+	req, err := http.NewRequest(http.MethodGet, "https://api.example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Anthropic-Version", "2023-06-01")
+	req.Header.Set("Authorization", "Bearer client")
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	// headers set by the client are kept, auth headers always win
+	for key, want := range map[string]string{"X-Project": "default", "Anthropic-Version": "2023-06-01", "Authorization": "Bearer token"} {
+		if value := got.Get(key); value != want {
+			t.Errorf("expected header %s %q, got %q", key, want, value)
+		}
+	}
+	if req.Header.Get("X-Project") != "" {
+		t.Error("the request of the client must not be modified")
+	}
+}
+
+// roundTripperFunc records the requests of a transport
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -9,32 +9,87 @@ import (
 type LLMType string
 
 const (
-	OpenAI   LLMType = "openai"
-	Deepseek LLMType = "deepseek"
+	OpenAI    LLMType = "openai"
+	Deepseek  LLMType = "deepseek"
+	Anthropic LLMType = "anthropic"
+	Gemini    LLMType = "gemini"
 )
 
 var (
-	OpenAIModels = []string{"gpt-3.5", "gpt-3.5-turbo"}
+	OpenAIModels    = []string{"gpt-4o-mini", "gpt-4o", "gpt-3.5-turbo"}
+	DeepseekModels  = []string{"deepseek-chat", "deepseek-reasoner"}
+	AnthropicModels = []string{"claude-3-5-haiku-latest", "claude-3-5-sonnet-latest"}
+	GeminiModels    = []string{"gemini-1.5-flash", "gemini-1.5-pro"}
 )
 
 var (
-	DefaultOpenAIModel string = "gpt-3.5-turbo"
+	DefaultOpenAIModel    string = "gpt-3.5-turbo"
+	DefaultDeepseekModel  string = "deepseek-chat"
+	DefaultAnthropicModel string = "claude-3-5-haiku-latest"
+	DefaultGeminiModel    string = "gemini-1.5-flash"
 )
 
 type LLM interface {
 	Type() LLMType
-	Call([]byte) (Response, error)
+	// Call sends the encoded ModelParams to the llm.
+	// The output is streamed into langchainllms.WithStreamingFunc if it is set in options.
+	Call(context.Context, []byte, ...langchainllms.CallOption) (Response, error)
 	Validate(context.Context, ...langchainllms.CallOption) (Response, error)
 }
 
+// ModelLister is implemented by llm clients which can list the models served by their api
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
 type Response interface {
 	Type() LLMType
 	String() string
 	Bytes() []byte
 	Unmarshal([]byte) error
+	// TokenUsage returns the token usage reported by the llm
+	TokenUsage() Usage
+	// GetData returns the text generated by the llm
+	GetData() string
+	// GetToolCalls returns the tool calls requested by the llm
+	GetToolCalls() []ToolCall
 }
 
 type ModelParams interface {
 	Marshal() []byte
 	Unmarshal([]byte) error
+	// IsIncremental reports whether the output should be streamed
+	IsIncremental() bool
+	// GetModel returns the model this call is sent to
+	GetModel() string
+	// PrependMessages inserts messages ahead of the prompt messages
+	PrependMessages(...Message) error
+}
+
+// Roles of provider neutral messages
+const (
+	MessageRoleSystem    = "system"
+	MessageRoleUser      = "user"
+	MessageRoleAssistant = "assistant"
+)
+
+// Message is a provider neutral chat message which providers convert into their own roles
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// StructuredOutputParams is implemented by the ModelParams of providers
+// which can constrain the output to json matching a schema
+type StructuredOutputParams interface {
+	SetResponseSchema(schema []byte)
+	// GetResponseSchema returns the schema the output is constrained to, nil if there is none
+	GetResponseSchema() []byte
+}
+
+// Usage is the token usage reported by a llm call
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
@@ -0,0 +1,209 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+const (
+	AnthropicModelAPIURL    = "https://api.anthropic.com/v1"
+	AnthropicAPIVersion     = "2023-06-01"
+	AnthropicDefaultTimeout = 300 * time.Second
+)
+
+// auth headers of the anthropic messages api
+const (
+	headerAPIKey  = "x-api-key"
+	headerVersion = "anthropic-version"
+)
+
+var (
+	_ llms.LLM         = (*Anthropic)(nil)
+	_ llms.ModelLister = (*Anthropic)(nil)
+)
+
+// Anthropic talks to the anthropic messages api
+type Anthropic struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewAnthropic(opts llms.ClientOptions) (*Anthropic, error) {
+	if !opts.HasCredentials() {
+		return nil, errors.New("credentials cannot be empty")
+	}
+	httpClient, err := opts.HTTPClient(AnthropicDefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	client := &Anthropic{
+		apiKey:     opts.APIKey,
+		baseURL:    strings.TrimSuffix(lo.Ternary(opts.BaseURL == "", AnthropicModelAPIURL, opts.BaseURL), "/"),
+		httpClient: httpClient,
+	}
+	return client, nil
+}
+
+func (a *Anthropic) Type() llms.LLMType {
+	return llms.Anthropic
+}
+
+func (a *Anthropic) Call(ctx context.Context, input []byte, options ...langchainllms.CallOption) (llms.Response, error) {
+	params := DefaultModelParams()
+	if err := params.Unmarshal(input); err != nil {
+		return nil, errors.Errorf("decode anthropic params: %v", err)
+	}
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	request, err := params.request()
+	if err != nil {
+		return nil, err
+	}
+	if request.Tools, err = tools(opts); err != nil {
+		return nil, err
+	}
+	resp, err := a.createMessage(ctx, request, opts.StreamingFunc)
+	if err != nil {
+		return nil, err
+	}
+	return resp.toResponse(), nil
+}
+
+func (a *Anthropic) Validate(ctx context.Context, options ...langchainllms.CallOption) (llms.Response, error) {
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	params := DefaultModelParams()
+	params.Model = lo.Ternary(opts.Model == "", params.Model, opts.Model)
+	params.MaxTokens = lo.Ternary(opts.MaxTokens == 0, params.MaxTokens, opts.MaxTokens)
+	params.Prompt = []Prompt{{Role: User, Content: "Hello"}}
+
+	request, err := params.request()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.createMessage(ctx, request, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.toResponse(), nil
+}
+
+// createMessage sends the request and streams the output into streamingFunc if it is set
+func (a *Anthropic) createMessage(ctx context.Context, request *messagesRequest,
+	streamingFunc func(ctx context.Context, chunk []byte) error) (*messagesResponse, error) {
+	request.Stream = streamingFunc != nil
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.apiKey != "" {
+		req.Header.Set(headerAPIKey, a.apiKey)
+	}
+	req.Header.Set(headerVersion, AnthropicAPIVersion)
+
+	r, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "call anthropic api")
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, llms.NewHTTPError("anthropic", r)
+	}
+
+	if streamingFunc != nil {
+		return readStream(ctx, r.Body, streamingFunc)
+	}
+	resp := &messagesResponse{}
+	if err := json.NewDecoder(r.Body).Decode(resp); err != nil {
+		return nil, errors.Errorf("decode anthropic response: %v", err)
+	}
+	return resp, nil
+}
+
+// streamErrorStatus maps the errors sent within a stream to the status codes they have outside of it
+var streamErrorStatus = map[string]int{
+	"rate_limit_error": http.StatusTooManyRequests,
+	"api_error":        http.StatusInternalServerError,
+	"overloaded_error": 529,
+}
+
+// readStream collects the streamed events into one response
+func readStream(ctx context.Context, body io.Reader, streamingFunc func(ctx context.Context, chunk []byte) error) (*messagesResponse, error) {
+	resp := &messagesResponse{}
+	// blocks are indexed by the order they are started in, their text and input arrive as deltas
+	var blocks []contentBlock
+	inputs := make(map[int]*strings.Builder)
+	err := llms.ReadEvents(body, func(_ string, data []byte) error {
+		event := &streamEvent{}
+		if err := json.Unmarshal(data, event); err != nil {
+			return errors.Errorf("decode anthropic stream event: %v", err)
+		}
+		switch event.Type {
+		case "message_start":
+			resp.ID = event.Message.ID
+			resp.Model = event.Message.Model
+			resp.Role = event.Message.Role
+			resp.Usage.InputTokens = event.Message.Usage.InputTokens
+		case "content_block_start":
+			for len(blocks) <= event.Index {
+				blocks = append(blocks, contentBlock{})
+			}
+			blocks[event.Index] = event.ContentBlock
+			blocks[event.Index].Input = nil
+			inputs[event.Index] = &strings.Builder{}
+		case "content_block_delta":
+			if event.Index >= len(blocks) {
+				return errors.Errorf("anthropic stream delta of unknown content block %d", event.Index)
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				blocks[event.Index].Text += event.Delta.Text
+				return streamingFunc(ctx, []byte(event.Delta.Text))
+			case "input_json_delta":
+				inputs[event.Index].WriteString(event.Delta.PartialJSON)
+			}
+		case "message_delta":
+			resp.StopReason = event.Delta.StopReason
+			resp.Usage.OutputTokens = event.Usage.OutputTokens
+		case "error":
+			return &llms.HTTPError{
+				StatusCode: streamErrorStatus[event.Error.Type],
+				Message:    "anthropic stream error: " + event.Error.Message,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range blocks {
+		if blocks[i].Type == "tool_use" {
+			blocks[i].Input = json.RawMessage(lo.Ternary(inputs[i].Len() == 0, "{}", inputs[i].String()))
+		}
+	}
+	resp.Content = blocks
+	return resp, nil
+}
@@ -0,0 +1,317 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+func newTestServer(t *testing.T, handler func(t *testing.T, req *messagesRequest) (int, any)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get(headerAPIKey); got != "test-key" {
+			t.Errorf("unexpected %s header %q", headerAPIKey, got)
+		}
+		if got := r.Header.Get(headerVersion); got != AnthropicAPIVersion {
+			t.Errorf("unexpected %s header %q", headerVersion, got)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("authorization header should not be set, got %q", got)
+		}
+		req := &messagesRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			// Fatal must not be called outside of the test goroutine
+			t.Errorf("decode request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		code, body := handler(t, req)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+}
+
+func TestAnthropicCall(t *testing.T) {
+	server := newTestServer(t, func(t *testing.T, req *messagesRequest) (int, any) {
+		if req.Model != "claude-test" || req.System != "be brief" || req.MaxTokens != DefaultMaxTokens {
+			t.Errorf("unexpected request %+v", req)
+		}
+		if len(req.Messages) != 2 || req.Messages[0].Role != "user" || req.Messages[1].Role != "assistant" {
+			t.Errorf("unexpected messages %+v", req.Messages)
+		}
+		return http.StatusOK, map[string]any{
+			"id":          "msg_1",
+			"role":        "assistant",
+			"content":     []map[string]string{{"type": "text", "text": "Hello"}, {"type": "text", "text": " there"}},
+			"stop_reason": "end_turn",
+			"usage":       map[string]int{"input_tokens": 3, "output_tokens": 2},
+		}
+	})
+	defer server.Close()
+
+	client, err := NewAnthropic(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL + "/v1/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := DefaultModelParams()
+	params.Model = "claude-test"
+	params.System = "be brief"
+	params.Prompt = []Prompt{{Role: User, Content: "Hi"}, {Role: Assistant, Content: "Hi!"}}
+
+	resp, err := client.Call(context.Background(), params.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := resp.(*Response)
+	if got.Data != "Hello there" || got.FinishReason != "end_turn" {
+		t.Errorf("unexpected response %+v", got)
+	}
+	if got.Usage.PromptTokens != 3 || got.Usage.CompletionTokens != 2 || got.Usage.TotalTokens != 5 {
+		t.Errorf("unexpected usage %+v", got.Usage)
+	}
+}
+
+func TestAnthropicValidateError(t *testing.T) {
+	server := newTestServer(t, func(t *testing.T, req *messagesRequest) (int, any) {
+		if req.Model != "claude-missing" {
+			t.Errorf("unexpected model %s", req.Model)
+		}
+		return http.StatusNotFound, map[string]any{
+			"type":  "error",
+			"error": map[string]string{"type": "not_found_error", "message": "model: claude-missing"},
+		}
+	})
+	defer server.Close()
+
+	client, err := NewAnthropic(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Validate(context.Background(), langchainllms.WithModel("claude-missing"))
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "model: claude-missing") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestAnthropicCallStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &messagesRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Errorf("decode request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !req.Stream {
+			t.Error("stream should be requested")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","role":"assistant","usage":{"input_tokens":3}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" there"}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`))
+	}))
+	defer server.Close()
+
+	client, err := NewAnthropic(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := DefaultModelParams()
+	params.Incremental = true
+	params.Prompt = []Prompt{{Role: User, Content: "Hi"}}
+
+	var chunks []string
+	resp, err := client.Call(context.Background(), params.Marshal(), langchainllms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+		chunks = append(chunks, string(chunk))
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(chunks, "|") != "Hello| there" {
+		t.Errorf("unexpected chunks %q", chunks)
+	}
+	got := resp.(*Response)
+	if got.Data != "Hello there" || got.FinishReason != "end_turn" || got.Usage.TotalTokens != 5 {
+		t.Errorf("unexpected response %+v", got)
+	}
+}
+
+func TestAnthropicCallTools(t *testing.T) {
+	server := newTestServer(t, func(t *testing.T, req *messagesRequest) (int, any) {
+		if len(req.Tools) != 1 || req.Tools[0].Name != "get_weather" || string(req.Tools[0].InputSchema) != `{"type":"object"}` {
+			t.Errorf("unexpected tools %+v", req.Tools)
+		}
+		if len(req.Messages) != 3 {
+			t.Errorf("unexpected messages %+v", req.Messages)
+			return http.StatusBadRequest, map[string]any{"error": map[string]string{"message": "unexpected messages"}}
+		}
+		if use := req.Messages[1].Content[0]; use.Type != "tool_use" || use.ID != "call_1" || string(use.Input) != `{"city":"Paris"}` {
+			t.Errorf("unexpected tool use %+v", use)
+		}
+		if result := req.Messages[2]; result.Role != "user" || result.Content[0].Type != "tool_result" || result.Content[0].ToolUseID != "call_1" {
+			t.Errorf("unexpected tool result %+v", result)
+		}
+		return http.StatusOK, map[string]any{
+			"content": []map[string]any{
+				{"type": "tool_use", "id": "call_2", "name": "get_weather", "input": map[string]string{"city": "Rome"}},
+			},
+			"stop_reason": "tool_use",
+		}
+	})
+	defer server.Close()
+
+	client, err := NewAnthropic(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := DefaultModelParams()
+	params.Prompt = []Prompt{
+		{Role: User, Content: "Weather in Paris and Rome?"},
+		{Role: Assistant, ToolCalls: []llms.ToolCall{{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}}},
+		{Role: Tool, ToolCallID: "call_1", Content: "sunny"},
+	}
+	resp, err := client.Call(context.Background(), params.Marshal(), llms.WithTools([]llms.Tool{{Name: "get_weather"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := resp.GetToolCalls()
+	if len(calls) != 1 || calls[0].ID != "call_2" || calls[0].Name != "get_weather" || calls[0].Arguments != `{"city":"Rome"}` {
+		t.Errorf("unexpected tool calls %+v", calls)
+	}
+}
+
+func TestAnthropicCallHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAnthropic(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := DefaultModelParams()
+	params.Prompt = []Prompt{{Role: User, Content: "Hi"}}
+
+	// the retries are decided by llms.RetryPolicy from the status and the Retry-After of the error
+	var httpErr *llms.HTTPError
+	_, err = client.Call(context.Background(), params.Marshal())
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests || httpErr.RetryAfter != time.Second {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.Contains(httpErr.Message, "slow down") {
+		t.Errorf("expected the message of the api in the error, got %q", httpErr.Message)
+	}
+}
+
+func TestAnthropicClientOptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Project"); got != "proj-1" {
+			t.Errorf("unexpected X-Project header %q", got)
+		}
+		// endpoint headers must not override the ones set by the client
+		if got := r.Header.Get(headerAPIKey); got != "test-key" {
+			t.Errorf("unexpected %s header %q", headerAPIKey, got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"Hello"}],"stop_reason":"end_turn"}`))
+	}))
+	defer server.Close()
+
+	params := DefaultModelParams()
+	params.Prompt = []Prompt{{Role: User, Content: "Hi"}}
+	headers := map[string]string{"X-Project": "proj-1", headerAPIKey: "other-key"}
+
+	// the self-signed certificate is rejected unless it is trusted
+	client, err := NewAnthropic(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL, Headers: headers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Call(context.Background(), params.Marshal()); err == nil {
+		t.Error("untrusted certificate should be rejected")
+	}
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	for _, opts := range []llms.ClientOptions{
+		{APIKey: "test-key", BaseURL: server.URL, Headers: headers, CABundle: caBundle},
+		{APIKey: "test-key", BaseURL: server.URL, Headers: headers, Insecure: true},
+	} {
+		client, err := NewAnthropic(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Call(context.Background(), params.Marshal())
+		if err != nil || resp.GetData() != "Hello" {
+			t.Errorf("unexpected response %v, error %v", resp, err)
+		}
+	}
+
+	if _, err := NewAnthropic(llms.ClientOptions{APIKey: "test-key", CABundle: []byte("invalid")}); err == nil {
+		t.Error("invalid ca bundle should be rejected")
+	}
+}
+
+func TestAnthropicListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/models" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get(headerAPIKey); got != "test-key" {
+			t.Errorf("unexpected %s header %q", headerAPIKey, got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("after_id") == "" {
+			_, _ = w.Write([]byte(`{"data":[{"id":"claude-a","type":"model"}],"has_more":true,"last_id":"claude-a"}`))
+			return
+		}
+		if got := r.URL.Query().Get("after_id"); got != "claude-a" {
+			t.Errorf("unexpected after_id %q", got)
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"claude-b","type":"model"}],"has_more":false,"last_id":"claude-b"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAnthropic(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	models, err := client.ListModels(context.Background())
+	if err != nil || strings.Join(models, ",") != "claude-a,claude-b" {
+		t.Errorf("unexpected models %v, error %v", models, err)
+	}
+}
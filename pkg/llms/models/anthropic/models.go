@@ -0,0 +1,68 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/pkg/errors"
+)
+
+// modelsPageSize is the largest page size of the anthropic models api
+const modelsPageSize = "1000"
+
+// modelList is a page of the anthropic models api
+type modelList struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
+}
+
+// ListModels lists the models of the anthropic models api, following all pages
+func (a *Anthropic) ListModels(ctx context.Context) ([]string, error) {
+	var models []string
+	query := url.Values{"limit": {modelsPageSize}}
+	for {
+		page, err := a.listModels(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, model := range page.Data {
+			models = append(models, model.ID)
+		}
+		if !page.HasMore || page.LastID == "" {
+			return models, nil
+		}
+		query.Set("after_id", page.LastID)
+	}
+}
+
+func (a *Anthropic) listModels(ctx context.Context, query url.Values) (*modelList, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+"/models?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if a.apiKey != "" {
+		req.Header.Set(headerAPIKey, a.apiKey)
+	}
+	req.Header.Set(headerVersion, AnthropicAPIVersion)
+
+	r, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "list anthropic models")
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, llms.NewHTTPError("anthropic", r)
+	}
+
+	page := &modelList{}
+	if err := json.NewDecoder(r.Body).Decode(page); err != nil {
+		return nil, errors.Errorf("decode anthropic model list: %v", err)
+	}
+	return page, nil
+}
@@ -0,0 +1,171 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+type Role string
+
+const (
+	User      Role = "user"
+	Assistant Role = "assistant"
+	// Tool messages carry the result of a tool call requested by the assistant.
+	// They are sent as tool_result blocks of a user message.
+	Tool Role = "tool"
+)
+
+// DefaultMaxTokens is required by the messages api which has no server side default
+const DefaultMaxTokens = 1024
+
+var _ llms.ModelParams = (*ModelParams)(nil)
+
+// +kubebuilder:object:generate=true
+type ModelParams struct {
+	// Model used for this prompt call
+	Model string `json:"model,omitempty"`
+
+	// System prompt which is sent apart from the messages
+	System string `json:"system,omitempty"`
+
+	// MaxTokens to generate before stopping
+	MaxTokens int `json:"max_tokens,omitempty"`
+
+	// Temperature is float in anthropic
+	Temperature float32 `json:"temperature,omitempty"`
+
+	// TopP is float in anthropic
+	TopP float32 `json:"top_p,omitempty"`
+
+	// Contents
+	Prompt []Prompt `json:"prompt"`
+
+	// Incremental streams the output while it is generated
+	Incremental bool `json:"incremental,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+type Prompt struct {
+	Role    Role   `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+	// ToolCallID is the id of the tool_use block a tool message answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// ToolCalls requested by an assistant message
+	ToolCalls []llms.ToolCall `json:"tool_calls,omitempty"`
+}
+
+func DefaultModelParams() ModelParams {
+	return ModelParams{
+		Model:     llms.DefaultAnthropicModel,
+		MaxTokens: DefaultMaxTokens,
+		Prompt:    []Prompt{},
+	}
+}
+
+func (params *ModelParams) Marshal() []byte {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return []byte{}
+	}
+	return data
+}
+
+func (params *ModelParams) Unmarshal(bytes []byte) error {
+	return json.Unmarshal(bytes, params)
+}
+
+func (params *ModelParams) IsIncremental() bool {
+	return params.Incremental
+}
+
+func (params *ModelParams) GetModel() string {
+	return params.Model
+}
+
+// PrependMessages inserts messages ahead of the prompt messages.
+// System messages are prepended to the system prompt instead.
+func (params *ModelParams) PrependMessages(messages ...llms.Message) error {
+	var systems []string
+	prompts := make([]Prompt, 0, len(messages)+len(params.Prompt))
+	for _, message := range messages {
+		switch message.Role {
+		case llms.MessageRoleSystem:
+			systems = append(systems, message.Content)
+		case llms.MessageRoleUser, llms.MessageRoleAssistant:
+			prompts = append(prompts, Prompt{Role: Role(message.Role), Content: message.Content})
+		default:
+			return errors.Errorf("unsupported message role %q", message.Role)
+		}
+	}
+	if params.System != "" {
+		systems = append(systems, params.System)
+	}
+	params.System = strings.Join(systems, "\n\n")
+	params.Prompt = append(prompts, params.Prompt...)
+	return nil
+}
+
+// request converts params into the wire format of the messages api
+func (params *ModelParams) request() (*messagesRequest, error) {
+	request := &messagesRequest{
+		Model:       params.Model,
+		System:      params.System,
+		MaxTokens:   params.MaxTokens,
+		Temperature: params.Temperature,
+		TopP:        params.TopP,
+		Messages:    make([]message, 0, len(params.Prompt)),
+	}
+	for _, prompt := range params.Prompt {
+		msg, err := prompt.message()
+		if err != nil {
+			return nil, err
+		}
+		request.Messages = append(request.Messages, msg)
+	}
+	return request, nil
+}
+
+func (prompt Prompt) message() (message, error) {
+	if prompt.Role == Tool {
+		if prompt.ToolCallID == "" {
+			return message{}, errors.New("tool prompt requires tool_call_id")
+		}
+		return message{
+			Role:    string(User),
+			Content: []contentBlock{{Type: "tool_result", ToolUseID: prompt.ToolCallID, Content: prompt.Content}},
+		}, nil
+	}
+	msg := message{Role: string(prompt.Role)}
+	if prompt.Content != "" || len(prompt.ToolCalls) == 0 {
+		msg.Content = append(msg.Content, contentBlock{Type: "text", Text: prompt.Content})
+	}
+	for _, call := range prompt.ToolCalls {
+		input := json.RawMessage(lo.Ternary(call.Arguments == "", "{}", call.Arguments))
+		if !json.Valid(input) {
+			return message{}, errors.Errorf("arguments of tool call %s are not valid json", call.ID)
+		}
+		msg.Content = append(msg.Content, contentBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: input})
+	}
+	return msg, nil
+}
+
+// tools converts the tools of the call options into the wire format of the messages api
+func tools(opts langchainllms.CallOptions) ([]tool, error) {
+	result := make([]tool, 0, len(opts.Tools))
+	for _, t := range opts.Tools {
+		if t.Function == nil {
+			continue
+		}
+		schema, err := llms.ToolParameters(t)
+		if err != nil {
+			return nil, errors.Errorf("encode parameters of tool %s: %v", t.Function.Name, err)
+		}
+		result = append(result, tool{Name: t.Function.Name, Description: t.Function.Description, InputSchema: schema})
+	}
+	return result, nil
+}
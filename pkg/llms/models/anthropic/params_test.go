@@ -0,0 +1,108 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+func TestPrependMessages(t *testing.T) {
+	tests := []struct {
+		name       string
+		system     string
+		messages   []llms.Message
+		wantSystem string
+		wantRoles  []Role
+		wantErr    bool
+	}{
+		{
+			name:      "chat messages",
+			messages:  []llms.Message{{Role: llms.MessageRoleUser, Content: "Hi"}, {Role: llms.MessageRoleAssistant, Content: "Hello"}},
+			wantRoles: []Role{User, Assistant, User},
+		},
+		{
+			name:       "system messages join the system prompt",
+			system:     "Be brief.",
+			messages:   []llms.Message{{Role: llms.MessageRoleSystem, Content: "You are a guide."}},
+			wantSystem: "You are a guide.\n\nBe brief.",
+			wantRoles:  []Role{User},
+		},
+		{name: "unsupported role", messages: []llms.Message{{Role: "function", Content: "{}"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := DefaultModelParams()
+			params.System = tt.system
+			params.Prompt = []Prompt{{Role: User, Content: "Where is the colosseum?"}}
+			err := params.PrependMessages(tt.messages...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if params.System != tt.wantSystem {
+				t.Errorf("expected system %q, got %q", tt.wantSystem, params.System)
+			}
+			roles := make([]Role, 0, len(params.Prompt))
+			for _, prompt := range params.Prompt {
+				roles = append(roles, prompt.Role)
+			}
+			if !reflect.DeepEqual(roles, tt.wantRoles) {
+				t.Errorf("expected roles %v, got %v", tt.wantRoles, roles)
+			}
+		})
+	}
+}
+
+func TestPromptMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  Prompt
+		want    string
+		wantErr bool
+	}{
+		{name: "text", prompt: Prompt{Role: User, Content: "Hi"}, want: `{"role":"user","content":[{"type":"text","text":"Hi"}]}`},
+		{
+			name:   "tool calls without text",
+			prompt: Prompt{Role: Assistant, ToolCalls: []llms.ToolCall{{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}}},
+			want:   `{"role":"assistant","content":[{"type":"tool_use","id":"call_1","name":"get_weather","input":{"city":"Paris"}}]}`,
+		},
+		{
+			name:   "tool call without arguments",
+			prompt: Prompt{Role: Assistant, Content: "Let me check.", ToolCalls: []llms.ToolCall{{ID: "call_1", Name: "now"}}},
+			want:   `{"role":"assistant","content":[{"type":"text","text":"Let me check."},{"type":"tool_use","id":"call_1","name":"now","input":{}}]}`,
+		},
+		{
+			name:   "tool result",
+			prompt: Prompt{Role: Tool, ToolCallID: "call_1", Content: "sunny"},
+			want:   `{"role":"user","content":[{"type":"tool_result","tool_use_id":"call_1","content":"sunny"}]}`,
+		},
+		{name: "tool result without call id", prompt: Prompt{Role: Tool, Content: "sunny"}, wantErr: true},
+		{
+			name:    "invalid tool call arguments",
+			prompt:  Prompt{Role: Assistant, ToolCalls: []llms.ToolCall{{ID: "call_1", Name: "get_weather", Arguments: `{"city":`}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := tt.prompt.message()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := json.Marshal(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
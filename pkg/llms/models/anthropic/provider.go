@@ -0,0 +1,19 @@
+package anthropic
+
+import (
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+func init() {
+	llms.MustRegister(llms.Provider{
+		Type: llms.Anthropic,
+		New: func(opts llms.ClientOptions) (llms.LLM, error) {
+			return NewAnthropic(opts)
+		},
+		DefaultModels: llms.AnthropicModels,
+		NewParams: func() llms.ModelParams {
+			params := DefaultModelParams()
+			return &params
+		},
+	})
+}
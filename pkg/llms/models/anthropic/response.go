@@ -0,0 +1,146 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+type Response struct {
+	Code         int        `json:"code"`
+	Data         string     `json:"data"`
+	Msg          string     `json:"msg"`
+	Success      bool       `json:"success"`
+	FinishReason string     `json:"finish_reason,omitempty"`
+	Usage        llms.Usage `json:"usage"`
+	// ToolCalls requested by the model
+	ToolCalls []llms.ToolCall `json:"tool_calls,omitempty"`
+}
+
+func (response *Response) Type() llms.LLMType {
+	return llms.Anthropic
+}
+
+func (response *Response) Bytes() []byte {
+	bytes, err := json.Marshal(response)
+	if err != nil {
+		return []byte{}
+	}
+	return bytes
+}
+
+func (response *Response) String() string {
+	return string(response.Bytes())
+}
+
+func (response *Response) Unmarshal(bytes []byte) error {
+	return json.Unmarshal(bytes, response)
+}
+
+func (response *Response) TokenUsage() llms.Usage {
+	return response.Usage
+}
+
+func (response *Response) GetData() string {
+	return response.Data
+}
+
+func (response *Response) GetToolCalls() []llms.ToolCall {
+	return response.ToolCalls
+}
+
+// messagesRequest is the request body of POST /v1/messages
+type messagesRequest struct {
+	Model       string    `json:"model"`
+	System      string    `json:"system,omitempty"`
+	Messages    []message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature float32   `json:"temperature,omitempty"`
+	TopP        float32   `json:"top_p,omitempty"`
+	Stream      bool      `json:"stream,omitempty"`
+	Tools       []tool    `json:"tools,omitempty"`
+}
+
+type message struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+type tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// messagesResponse is the response body of POST /v1/messages
+type messagesResponse struct {
+	ID         string         `json:"id"`
+	Model      string         `json:"model"`
+	Role       string         `json:"role"`
+	Content    []contentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      usage          `json:"usage"`
+}
+
+type contentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	// tool_use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+	// tool_result blocks
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+type usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// streamEvent is a server-sent event of a streamed message
+type streamEvent struct {
+	Type         string           `json:"type"`
+	Message      messagesResponse `json:"message"`
+	Index        int              `json:"index"`
+	ContentBlock contentBlock     `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage usage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (resp *messagesResponse) toResponse() *Response {
+	var text strings.Builder
+	var toolCalls []llms.ToolCall
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "tool_use":
+			toolCalls = append(toolCalls, llms.ToolCall{ID: block.ID, Name: block.Name, Arguments: string(block.Input)})
+		}
+	}
+	return &Response{
+		Code:         200,
+		Data:         text.String(),
+		Msg:          "call anthropic model success",
+		Success:      true,
+		FinishReason: resp.StopReason,
+		Usage: llms.Usage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		},
+		ToolCalls: toolCalls,
+	}
+}
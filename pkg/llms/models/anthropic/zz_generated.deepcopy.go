@@ -0,0 +1,67 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package anthropic
+
+import (
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelParams) DeepCopyInto(out *ModelParams) {
+	*out = *in
+	if in.Prompt != nil {
+		in, out := &in.Prompt, &out.Prompt
+		*out = make([]Prompt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelParams.
+func (in *ModelParams) DeepCopy() *ModelParams {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Prompt) DeepCopyInto(out *Prompt) {
+	*out = *in
+	if in.ToolCalls != nil {
+		in, out := &in.ToolCalls, &out.ToolCalls
+		*out = make([]llms.ToolCall, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Prompt.
+func (in *Prompt) DeepCopy() *Prompt {
+	if in == nil {
+		return nil
+	}
+	out := new(Prompt)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,87 @@
+package deepseek
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/fleezesd/llm-operator/pkg/llms/models/openai"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+const (
+	DeepseekModelAPIURL = "https://api.deepseek.com/v1"
+)
+
+type Method string
+
+const (
+	DeepseekInvoke Method = "invoke"
+)
+
+var (
+	_ llms.LLM         = (*Deepseek)(nil)
+	_ llms.ModelLister = (*Deepseek)(nil)
+)
+
+// Deepseek talks to the deepseek api which is compatible with openai chat completions
+type Deepseek struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewDeepseek(opts llms.ClientOptions) (*Deepseek, error) {
+	if !opts.HasCredentials() {
+		return nil, errors.New("credentials cannot be empty")
+	}
+	httpClient, err := opts.HTTPClient(openai.OpenAIDefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	client := &Deepseek{
+		apiKey:     opts.APIKey,
+		baseURL:    lo.Ternary(opts.BaseURL == "", DeepseekModelAPIURL, opts.BaseURL),
+		httpClient: httpClient,
+	}
+	return client, nil
+}
+
+func (d *Deepseek) Type() llms.LLMType {
+	return llms.Deepseek
+}
+
+func (d *Deepseek) Call(ctx context.Context, input []byte, options ...langchainllms.CallOption) (llms.Response, error) {
+	params := DefaultModelParams()
+	if err := params.Unmarshal(input); err != nil {
+		return nil, errors.Errorf("decode deepseek params: %v", err)
+	}
+	messages, err := params.MessageContents()
+	if err != nil {
+		return nil, err
+	}
+
+	choice, err := openai.ChatCompletion(ctx, d.httpClient, d.apiKey, d.baseURL, messages, nil, append(params.CallOptions(), options...)...)
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(choice), nil
+}
+
+func (d *Deepseek) Validate(ctx context.Context, options ...langchainllms.CallOption) (llms.Response, error) {
+	messages := []langchainllms.MessageContent{
+		langchainllms.TextParts(langchainllms.ChatMessageTypeHuman, "Hello"),
+	}
+	options = append([]langchainllms.CallOption{langchainllms.WithModel(llms.DefaultDeepseekModel)}, options...)
+	choice, err := openai.ChatCompletion(ctx, d.httpClient, d.apiKey, d.baseURL, messages, nil, options...)
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(choice), nil
+}
+
+func (d *Deepseek) ListModels(ctx context.Context) ([]string, error) {
+	return openai.ListModels(ctx, d.httpClient, d.apiKey, d.baseURL)
+}
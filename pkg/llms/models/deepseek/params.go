@@ -0,0 +1,159 @@
+package deepseek
+
+import (
+	"encoding/json"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/fleezesd/llm-operator/pkg/llms/models/openai"
+	"github.com/pkg/errors"
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type Role string
+
+const (
+	System    Role = "system"
+	User      Role = "user"
+	Assistant Role = "assistant"
+	// Tool messages carry the result of a tool call requested by the assistant
+	Tool Role = "tool"
+)
+
+var (
+	_ llms.ModelParams            = (*ModelParams)(nil)
+	_ llms.StructuredOutputParams = (*ModelParams)(nil)
+)
+
+// +kubebuilder:object:generate=true
+type ModelParams struct {
+	// Method used for this prompt call
+	Method Method `json:"method,omitempty"`
+
+	// Model used for this prompt call
+	Model string `json:"model,omitempty"`
+
+	// Temperature is float in deepseek
+	Temperature float32 `json:"temperature,omitempty"`
+
+	// TopP is float in deepseek
+	TopP float32 `json:"top_p,omitempty"`
+
+	// Contents
+	Prompt []Prompt `json:"prompt"`
+
+	// Incremental is only Used for SSE Invoke
+	Incremental bool `json:"incremental,omitempty"`
+
+	// ResponseSchema requests json output. Deepseek only supports json_object mode,
+	// so the schema is described to the model in a system message.
+	// It is replaced by spec.responseSchema of the prompt if that is set.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	ResponseSchema *runtime.RawExtension `json:"response_schema,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+type Prompt struct {
+	Role    Role   `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+	// ToolCallID is the id of the tool call a tool message answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// ToolCalls requested by an assistant message
+	ToolCalls []llms.ToolCall `json:"tool_calls,omitempty"`
+}
+
+func DefaultModelParams() ModelParams {
+	return ModelParams{
+		Model:       llms.DefaultDeepseekModel,
+		Method:      DeepseekInvoke,
+		Temperature: 1.0,
+		TopP:        1.0,
+		Prompt:      []Prompt{},
+	}
+}
+
+func (params *ModelParams) Marshal() []byte {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return []byte{}
+	}
+	return data
+}
+
+func (params *ModelParams) Unmarshal(bytes []byte) error {
+	return json.Unmarshal(bytes, params)
+}
+
+func (params *ModelParams) IsIncremental() bool {
+	return params.Incremental
+}
+
+func (params *ModelParams) GetModel() string {
+	return params.Model
+}
+
+func (params *ModelParams) PrependMessages(messages ...llms.Message) error {
+	prompts := make([]Prompt, 0, len(messages)+len(params.Prompt))
+	for _, message := range messages {
+		prompts = append(prompts, Prompt{Role: Role(message.Role), Content: message.Content})
+	}
+	params.Prompt = append(prompts, params.Prompt...)
+	return nil
+}
+
+func (params *ModelParams) SetResponseSchema(schema []byte) {
+	params.ResponseSchema = &runtime.RawExtension{Raw: schema}
+}
+
+func (params *ModelParams) GetResponseSchema() []byte {
+	if params.ResponseSchema == nil {
+		return nil
+	}
+	return params.ResponseSchema.Raw
+}
+
+// MessageContents converts the prompts into chat messages.
+// Deepseek rejects json output unless the prompt asks for json, so a response schema
+// is prepended as a system message asking for it.
+func (params *ModelParams) MessageContents() ([]langchainllms.MessageContent, error) {
+	messages := make([]langchainllms.MessageContent, 0, len(params.Prompt)+1)
+	if params.ResponseSchema != nil {
+		messages = append(messages, langchainllms.TextParts(langchainllms.ChatMessageTypeSystem,
+			"Respond with a json object matching this json schema:\n"+string(params.ResponseSchema.Raw)))
+	}
+	for _, prompt := range params.Prompt {
+		var role langchainllms.ChatMessageType
+		switch prompt.Role {
+		case System:
+			role = langchainllms.ChatMessageTypeSystem
+		case User, "":
+			role = langchainllms.ChatMessageTypeHuman
+		case Assistant:
+			role = langchainllms.ChatMessageTypeAI
+		case Tool:
+			if prompt.ToolCallID == "" {
+				return nil, errors.New("tool prompt requires tool_call_id")
+			}
+			role = langchainllms.ChatMessageTypeTool
+		default:
+			return nil, errors.Errorf("unsupported prompt role %q", prompt.Role)
+		}
+		messages = append(messages, openai.ChatMessage(role, prompt.Content, prompt.ToolCallID, prompt.ToolCalls))
+	}
+	return messages, nil
+}
+
+// CallOptions converts the params into chat completion options
+func (params *ModelParams) CallOptions() []langchainllms.CallOption {
+	options := []langchainllms.CallOption{
+		langchainllms.WithModel(params.Model),
+		langchainllms.WithTemperature(openai.Float64(params.Temperature)),
+		langchainllms.WithTopP(openai.Float64(params.TopP)),
+	}
+	if params.ResponseSchema != nil {
+		// deepseek only supports json_object mode, the schema is described in a system message
+		options = append(options, langchainllms.WithJSONMode())
+	}
+	return options
+}
@@ -0,0 +1,47 @@
+package deepseek
+
+import (
+	"strings"
+	"testing"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+func TestMessageContentsResponseSchema(t *testing.T) {
+	schema := `{"type":"object","properties":{"city":{"type":"string"}}}`
+	tests := []struct {
+		name       string
+		schema     string
+		wantHint   bool
+		wantLength int
+	}{
+		{name: "no response schema", wantLength: 1},
+		{name: "response schema", schema: schema, wantHint: true, wantLength: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := DefaultModelParams()
+			params.Prompt = []Prompt{{Role: User, Content: "Where is the colosseum?"}}
+			if tt.schema != "" {
+				params.SetResponseSchema([]byte(tt.schema))
+			}
+			messages, err := params.MessageContents()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(messages) != tt.wantLength {
+				t.Fatalf("expected %d messages, got %d", tt.wantLength, len(messages))
+			}
+			if !tt.wantHint {
+				return
+			}
+			// deepseek answers 400 in json mode unless the prompt mentions json
+			hint := messages[0]
+			text, ok := hint.Parts[0].(langchainllms.TextContent)
+			if hint.Role != langchainllms.ChatMessageTypeSystem || !ok ||
+				!strings.Contains(text.Text, "json") || !strings.Contains(text.Text, schema) {
+				t.Errorf("expected a system message asking for json of the schema, got %+v", hint)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package deepseek
+
+import (
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+func init() {
+	llms.MustRegister(llms.Provider{
+		Type: llms.Deepseek,
+		New: func(opts llms.ClientOptions) (llms.LLM, error) {
+			return NewDeepseek(opts)
+		},
+		DefaultModels: llms.DeepseekModels,
+		NewParams: func() llms.ModelParams {
+			params := DefaultModelParams()
+			return &params
+		},
+	})
+}
@@ -0,0 +1,64 @@
+package deepseek
+
+import (
+	"encoding/json"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/fleezesd/llm-operator/pkg/llms/models/openai"
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+type Response struct {
+	Code         int        `json:"code"`
+	Data         string     `json:"data"`
+	Msg          string     `json:"msg"`
+	Success      bool       `json:"success"`
+	FinishReason string     `json:"finish_reason,omitempty"`
+	Usage        llms.Usage `json:"usage"`
+	// ToolCalls requested by the model
+	ToolCalls []llms.ToolCall `json:"tool_calls,omitempty"`
+}
+
+func newResponse(choice *langchainllms.ContentChoice) *Response {
+	return &Response{
+		Code:         200,
+		Data:         choice.Content,
+		Msg:          "call deepseek model success",
+		Success:      true,
+		FinishReason: choice.StopReason,
+		Usage:        openai.UsageFromChoice(choice),
+		ToolCalls:    openai.ToolCallsFromChoice(choice),
+	}
+}
+
+func (response *Response) Type() llms.LLMType {
+	return llms.Deepseek
+}
+
+func (response *Response) Bytes() []byte {
+	bytes, err := json.Marshal(response)
+	if err != nil {
+		return []byte{}
+	}
+	return bytes
+}
+
+func (response *Response) String() string {
+	return string(response.Bytes())
+}
+
+func (response *Response) Unmarshal(bytes []byte) error {
+	return json.Unmarshal(bytes, response)
+}
+
+func (response *Response) TokenUsage() llms.Usage {
+	return response.Usage
+}
+
+func (response *Response) GetData() string {
+	return response.Data
+}
+
+func (response *Response) GetToolCalls() []llms.ToolCall {
+	return response.ToolCalls
+}
@@ -0,0 +1,193 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+const (
+	GeminiModelAPIURL    = "https://generativelanguage.googleapis.com/v1beta"
+	GeminiDefaultTimeout = 300 * time.Second
+)
+
+// headerAPIKey carries the api key instead of the `key` query parameter
+// so that it does not show up in logged urls
+const headerAPIKey = "x-goog-api-key"
+
+var (
+	_ llms.LLM         = (*Gemini)(nil)
+	_ llms.ModelLister = (*Gemini)(nil)
+)
+
+// Gemini talks to the google gemini generateContent api
+type Gemini struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewGemini(opts llms.ClientOptions) (*Gemini, error) {
+	if !opts.HasCredentials() {
+		return nil, errors.New("credentials cannot be empty")
+	}
+	httpClient, err := opts.HTTPClient(GeminiDefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	client := &Gemini{
+		apiKey:     opts.APIKey,
+		baseURL:    strings.TrimSuffix(lo.Ternary(opts.BaseURL == "", GeminiModelAPIURL, opts.BaseURL), "/"),
+		httpClient: httpClient,
+	}
+	return client, nil
+}
+
+func (g *Gemini) Type() llms.LLMType {
+	return llms.Gemini
+}
+
+func (g *Gemini) Call(ctx context.Context, input []byte, options ...langchainllms.CallOption) (llms.Response, error) {
+	params := DefaultModelParams()
+	if err := params.Unmarshal(input); err != nil {
+		return nil, errors.Errorf("decode gemini params: %v", err)
+	}
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	request, err := params.request()
+	if err != nil {
+		return nil, err
+	}
+	if request.Tools, err = tools(opts); err != nil {
+		return nil, err
+	}
+	resp, err := g.generateContent(ctx, params.Model, request, opts.StreamingFunc)
+	if err != nil {
+		return nil, err
+	}
+	return resp.toResponse(), nil
+}
+
+func (g *Gemini) Validate(ctx context.Context, options ...langchainllms.CallOption) (llms.Response, error) {
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	params := DefaultModelParams()
+	params.Model = lo.Ternary(opts.Model == "", params.Model, opts.Model)
+	params.MaxOutputTokens = opts.MaxTokens
+	params.Prompt = []Prompt{{Role: User, Content: "Hello"}}
+
+	request, err := params.request()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.generateContent(ctx, params.Model, request, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.toResponse(), nil
+}
+
+// generateContent sends the request and streams the output into streamingFunc if it is set
+func (g *Gemini) generateContent(ctx context.Context, model string, request *generateContentRequest,
+	streamingFunc func(ctx context.Context, chunk []byte) error) (*generateContentResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", g.baseURL, url.PathEscape(model))
+	if streamingFunc != nil {
+		endpoint = fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", g.baseURL, url.PathEscape(model))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set(headerAPIKey, g.apiKey)
+	}
+
+	r, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "call gemini api")
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, llms.NewHTTPError("gemini", r)
+	}
+
+	resp := &generateContentResponse{}
+	if streamingFunc != nil {
+		resp, err = readStream(ctx, r.Body, streamingFunc)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(resp); err != nil {
+		return nil, errors.Errorf("decode gemini response: %v", err)
+	}
+	if len(resp.Candidates) == 0 {
+		return nil, errors.New("gemini api returned no candidates")
+	}
+	return resp, nil
+}
+
+// readStream merges the streamed chunks into one response.
+// Every chunk is a generateContentResponse carrying the next part of the first candidate.
+func readStream(ctx context.Context, body io.Reader, streamingFunc func(ctx context.Context, chunk []byte) error) (*generateContentResponse, error) {
+	resp := &generateContentResponse{}
+	text := &strings.Builder{}
+	merged := candidate{Content: content{Role: string(Model)}}
+	var calls []part
+	err := llms.ReadEvents(body, func(_ string, data []byte) error {
+		chunk := &generateContentResponse{}
+		if err := json.Unmarshal(data, chunk); err != nil {
+			return errors.Errorf("decode gemini stream chunk: %v", err)
+		}
+		resp.UsageMetadata = chunk.UsageMetadata
+		if len(chunk.Candidates) == 0 {
+			return nil
+		}
+		if reason := chunk.Candidates[0].FinishReason; reason != "" {
+			merged.FinishReason = reason
+		}
+		for _, p := range chunk.Candidates[0].Content.Parts {
+			if p.FunctionCall != nil {
+				calls = append(calls, p)
+			}
+			if p.Text == "" {
+				continue
+			}
+			text.WriteString(p.Text)
+			if err := streamingFunc(ctx, []byte(p.Text)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if text.Len() > 0 || len(calls) > 0 || merged.FinishReason != "" {
+		merged.Content.Parts = append([]part{{Text: text.String()}}, calls...)
+		resp.Candidates = []candidate{merged}
+	}
+	return resp, nil
+}
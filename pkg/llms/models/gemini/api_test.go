@@ -0,0 +1,288 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+func newTestServer(t *testing.T, model string, handler func(t *testing.T, req *generateContentRequest) (int, any)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want := "/v1beta/models/" + model + ":generateContent"; r.URL.Path != want {
+			t.Errorf("unexpected path %s, want %s", r.URL.Path, want)
+		}
+		if got := r.Header.Get(headerAPIKey); got != "test-key" {
+			t.Errorf("unexpected %s header %q", headerAPIKey, got)
+		}
+		if r.URL.Query().Has("key") {
+			t.Error("api key should not be sent as query parameter")
+		}
+		req := &generateContentRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			// Fatal must not be called outside of the test goroutine
+			t.Errorf("decode request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		code, body := handler(t, req)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+}
+
+func TestGeminiCall(t *testing.T) {
+	server := newTestServer(t, "gemini-test", func(t *testing.T, req *generateContentRequest) (int, any) {
+		if req.SystemInstruction == nil || req.SystemInstruction.Parts[0].Text != "be brief" {
+			t.Errorf("unexpected system instruction %+v", req.SystemInstruction)
+		}
+		if len(req.Contents) != 2 || req.Contents[0].Role != "user" || req.Contents[1].Role != "model" {
+			t.Errorf("unexpected contents %+v", req.Contents)
+		}
+		if req.GenerationConfig == nil || req.GenerationConfig.Temperature != 0.5 {
+			t.Errorf("unexpected generation config %+v", req.GenerationConfig)
+		}
+		return http.StatusOK, map[string]any{
+			"candidates": []map[string]any{{
+				"content":      map[string]any{"role": "model", "parts": []map[string]string{{"text": "Hello"}, {"text": " there"}}},
+				"finishReason": "STOP",
+			}},
+			"usageMetadata": map[string]int{"promptTokenCount": 3, "candidatesTokenCount": 2, "totalTokenCount": 5},
+		}
+	})
+	defer server.Close()
+
+	client, err := NewGemini(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL + "/v1beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := DefaultModelParams()
+	params.Model = "gemini-test"
+	params.System = "be brief"
+	params.Temperature = 0.5
+	params.Prompt = []Prompt{{Role: User, Content: "Hi"}, {Role: Model, Content: "Hi!"}}
+
+	resp, err := client.Call(context.Background(), params.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := resp.(*Response)
+	if got.Data != "Hello there" || got.FinishReason != "STOP" {
+		t.Errorf("unexpected response %+v", got)
+	}
+	if got.Usage.PromptTokens != 3 || got.Usage.CompletionTokens != 2 || got.Usage.TotalTokens != 5 {
+		t.Errorf("unexpected usage %+v", got.Usage)
+	}
+}
+
+func TestGeminiValidateError(t *testing.T) {
+	server := newTestServer(t, "gemini-missing", func(t *testing.T, req *generateContentRequest) (int, any) {
+		return http.StatusBadRequest, map[string]any{
+			"error": map[string]any{"code": 400, "message": "API key not valid", "status": "INVALID_ARGUMENT"},
+		}
+	})
+	defer server.Close()
+
+	client, err := NewGemini(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL + "/v1beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Validate(context.Background(), langchainllms.WithModel("gemini-missing"))
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "API key not valid") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestGeminiCallStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-test:streamGenerateContent" || r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("unexpected url %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}],"usageMetadata":{"promptTokenCount":3,"totalTokenCount":3}}
+
+data: {"candidates":[{"content":{"role":"model","parts":[{"text":" there"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":2,"totalTokenCount":5}}
+
+`))
+	}))
+	defer server.Close()
+
+	client, err := NewGemini(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := DefaultModelParams()
+	params.Model = "gemini-test"
+	params.Incremental = true
+	params.Prompt = []Prompt{{Role: User, Content: "Hi"}}
+
+	var chunks []string
+	resp, err := client.Call(context.Background(), params.Marshal(), langchainllms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+		chunks = append(chunks, string(chunk))
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(chunks, "|") != "Hello| there" {
+		t.Errorf("unexpected chunks %q", chunks)
+	}
+	got := resp.(*Response)
+	if got.Data != "Hello there" || got.FinishReason != "STOP" || got.Usage.TotalTokens != 5 {
+		t.Errorf("unexpected response %+v", got)
+	}
+}
+
+func TestGeminiCallTools(t *testing.T) {
+	server := newTestServer(t, "gemini-test", func(t *testing.T, req *generateContentRequest) (int, any) {
+		if len(req.Tools) != 1 || len(req.Tools[0].FunctionDeclarations) != 1 ||
+			string(req.Tools[0].FunctionDeclarations[0].Parameters) != `{"properties":{"city":{"type":"string"}},"type":"object"}` {
+			t.Errorf("unexpected tools %+v", req.Tools)
+		}
+		if len(req.Contents) != 3 {
+			t.Errorf("unexpected contents %+v", req.Contents)
+			return http.StatusBadRequest, map[string]any{"error": map[string]string{"message": "unexpected contents"}}
+		}
+		if call := req.Contents[1].Parts[0].FunctionCall; call == nil || call.Name != "get_weather" || string(call.Args) != `{"city":"Paris"}` {
+			t.Errorf("unexpected function call %+v", req.Contents[1].Parts)
+		}
+		if response := req.Contents[2].Parts[0].FunctionResponse; response == nil || response.Name != "get_weather" ||
+			string(response.Response) != `{"content":"sunny"}` {
+			t.Errorf("unexpected function response %+v", req.Contents[2].Parts)
+		}
+		return http.StatusOK, map[string]any{
+			"candidates": []map[string]any{{
+				"content": map[string]any{"role": "model", "parts": []map[string]any{
+					{"functionCall": map[string]any{"name": "get_weather", "args": map[string]string{"city": "Rome"}}},
+				}},
+				"finishReason": "STOP",
+			}},
+		}
+	})
+	defer server.Close()
+
+	client, err := NewGemini(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL + "/v1beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := DefaultModelParams()
+	params.Model = "gemini-test"
+	params.Prompt = []Prompt{
+		{Role: User, Content: "Weather in Paris and Rome?"},
+		{Role: Model, ToolCalls: []llms.ToolCall{{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}}},
+		{Role: Tool, ToolCallID: "call_1", Content: "sunny"},
+	}
+	tool := llms.Tool{Name: "get_weather", Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"additionalProperties":false}`)}
+	resp, err := client.Call(context.Background(), params.Marshal(), llms.WithTools([]llms.Tool{tool}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := resp.GetToolCalls()
+	if len(calls) != 1 || !strings.HasPrefix(calls[0].ID, "call_") || calls[0].Name != "get_weather" || calls[0].Arguments != `{"city":"Rome"}` {
+		t.Errorf("unexpected tool calls %+v", calls)
+	}
+}
+
+func TestGeminiCallResponseSchema(t *testing.T) {
+	schema := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"city":{"type":"string"}},"additionalProperties":false}`
+	// keywords gemini does not know are dropped
+	sent := `{"properties":{"city":{"type":"string"}},"type":"object"}`
+	server := newTestServer(t, "gemini-test", func(t *testing.T, req *generateContentRequest) (int, any) {
+		if req.GenerationConfig.ResponseMIMEType != "application/json" || string(req.GenerationConfig.ResponseSchema) != sent {
+			t.Errorf("unexpected generation config %+v", req.GenerationConfig)
+		}
+		return http.StatusOK, map[string]any{
+			"candidates": []map[string]any{{
+				"content": map[string]any{"role": "model", "parts": []map[string]string{{"text": `{"city":"Rome"}`}}},
+			}},
+		}
+	})
+	defer server.Close()
+
+	client, err := NewGemini(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL + "/v1beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := DefaultModelParams()
+	params.Model = "gemini-test"
+	params.Prompt = []Prompt{{Role: User, Content: "Where is the colosseum?"}}
+	params.SetResponseSchema([]byte(schema))
+	resp, err := client.Call(context.Background(), params.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := llms.ParseResponse([]byte(schema), resp.GetData()); err != nil {
+		t.Errorf("unexpected response %q: %v", resp.GetData(), err)
+	}
+	if _, err := llms.ParseResponse([]byte(`{"type":"object","required":["country"]}`), resp.GetData()); err == nil {
+		t.Error("response without required property should not match")
+	}
+}
+
+func TestGeminiAuthHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get(headerAPIKey); got != "" {
+			t.Errorf("%s header should not be set without api key, got %q", headerAPIKey, got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("unexpected authorization header %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewGemini(llms.ClientOptions{BaseURL: server.URL}); err == nil {
+		t.Error("client without credentials should be rejected")
+	}
+	client, err := NewGemini(llms.ClientOptions{
+		BaseURL:     server.URL,
+		AuthHeaders: map[string]string{"Authorization": "Bearer test-token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := DefaultModelParams()
+	params.Prompt = []Prompt{{Role: User, Content: "Hi"}}
+	resp, err := client.Call(context.Background(), params.Marshal())
+	if err != nil || resp.GetData() != "Hello" {
+		t.Errorf("unexpected response %v, error %v", resp, err)
+	}
+}
+
+func TestGeminiListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1beta/models" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get(headerAPIKey); got != "test-key" {
+			t.Errorf("unexpected %s header %q", headerAPIKey, got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("pageToken") == "" {
+			_, _ = w.Write([]byte(`{"models":[
+				{"name":"models/gemini-a","supportedGenerationMethods":["generateContent","countTokens"]},
+				{"name":"models/embedding-a","supportedGenerationMethods":["embedContent"]}
+			],"nextPageToken":"next"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"models":[{"name":"models/gemini-b","supportedGenerationMethods":["generateContent"]}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewGemini(llms.ClientOptions{APIKey: "test-key", BaseURL: server.URL + "/v1beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	models, err := client.ListModels(context.Background())
+	if err != nil || strings.Join(models, ",") != "gemini-a,gemini-b" {
+		t.Errorf("unexpected models %v, error %v", models, err)
+	}
+}
@@ -0,0 +1,75 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// modelsPageSize is the largest page size of the gemini models api
+const modelsPageSize = "1000"
+
+// generateContentMethod is listed in the supported methods of the models prompts can be sent to
+const generateContentMethod = "generateContent"
+
+// modelList is a page of the gemini models api
+type modelList struct {
+	Models []struct {
+		// Name of the model in the form models/{model}
+		Name                       string   `json:"name"`
+		SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+	} `json:"models"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// ListModels lists the models of the gemini models api which support generateContent, following all pages
+func (g *Gemini) ListModels(ctx context.Context) ([]string, error) {
+	var models []string
+	query := url.Values{"pageSize": {modelsPageSize}}
+	for {
+		page, err := g.listModels(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, model := range page.Models {
+			if lo.Contains(model.SupportedGenerationMethods, generateContentMethod) {
+				models = append(models, strings.TrimPrefix(model.Name, "models/"))
+			}
+		}
+		if page.NextPageToken == "" {
+			return models, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+func (g *Gemini) listModels(ctx context.Context, query url.Values) (*modelList, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"/models?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if g.apiKey != "" {
+		req.Header.Set(headerAPIKey, g.apiKey)
+	}
+
+	r, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "list gemini models")
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, llms.NewHTTPError("gemini", r)
+	}
+
+	page := &modelList{}
+	if err := json.NewDecoder(r.Body).Decode(page); err != nil {
+		return nil, errors.Errorf("decode gemini model list: %v", err)
+	}
+	return page, nil
+}
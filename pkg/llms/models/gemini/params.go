@@ -0,0 +1,234 @@
+package gemini
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type Role string
+
+const (
+	User Role = "user"
+	// Model is the role gemini uses for assistant turns
+	Model Role = "model"
+	// Tool messages carry the result of a function call requested by the model.
+	// They are sent as functionResponse parts of a user content.
+	Tool Role = "tool"
+)
+
+var (
+	_ llms.ModelParams            = (*ModelParams)(nil)
+	_ llms.StructuredOutputParams = (*ModelParams)(nil)
+)
+
+// +kubebuilder:object:generate=true
+type ModelParams struct {
+	// Model used for this prompt call
+	Model string `json:"model,omitempty"`
+
+	// System instruction which is sent apart from the contents
+	System string `json:"system,omitempty"`
+
+	// MaxOutputTokens to generate before stopping
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+
+	// Temperature is float in gemini
+	Temperature float32 `json:"temperature,omitempty"`
+
+	// TopP is float in gemini
+	TopP float32 `json:"top_p,omitempty"`
+
+	// Contents
+	Prompt []Prompt `json:"prompt"`
+
+	// Incremental streams the output while it is generated
+	Incremental bool `json:"incremental,omitempty"`
+
+	// ResponseSchema constrains the output to json matching this json schema,
+	// it is translated into the openapi subset gemini supports.
+	// The schema in spec.responseSchema of the prompt takes its place, the output is validated against the one sent.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	ResponseSchema *runtime.RawExtension `json:"response_schema,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+type Prompt struct {
+	Role    Role   `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+	// ToolCallID is the id of the function call a tool message answers.
+	// Gemini has no call ids, so ids of its tool calls are the function names.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// ToolCalls requested by a model message
+	ToolCalls []llms.ToolCall `json:"tool_calls,omitempty"`
+}
+
+func DefaultModelParams() ModelParams {
+	return ModelParams{
+		Model:  llms.DefaultGeminiModel,
+		Prompt: []Prompt{},
+	}
+}
+
+func (params *ModelParams) Marshal() []byte {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return []byte{}
+	}
+	return data
+}
+
+func (params *ModelParams) Unmarshal(bytes []byte) error {
+	return json.Unmarshal(bytes, params)
+}
+
+func (params *ModelParams) IsIncremental() bool {
+	return params.Incremental
+}
+
+func (params *ModelParams) GetModel() string {
+	return params.Model
+}
+
+// PrependMessages inserts messages ahead of the prompt messages.
+// System messages are prepended to the system instruction instead.
+func (params *ModelParams) PrependMessages(messages ...llms.Message) error {
+	var systems []string
+	prompts := make([]Prompt, 0, len(messages)+len(params.Prompt))
+	for _, message := range messages {
+		switch message.Role {
+		case llms.MessageRoleSystem:
+			systems = append(systems, message.Content)
+		case llms.MessageRoleUser:
+			prompts = append(prompts, Prompt{Role: User, Content: message.Content})
+		case llms.MessageRoleAssistant, string(Model):
+			prompts = append(prompts, Prompt{Role: Model, Content: message.Content})
+		default:
+			return errors.Errorf("unsupported message role %q", message.Role)
+		}
+	}
+	if params.System != "" {
+		systems = append(systems, params.System)
+	}
+	params.System = strings.Join(systems, "\n\n")
+	params.Prompt = append(prompts, params.Prompt...)
+	return nil
+}
+
+func (params *ModelParams) SetResponseSchema(schema []byte) {
+	params.ResponseSchema = &runtime.RawExtension{Raw: schema}
+}
+
+func (params *ModelParams) GetResponseSchema() []byte {
+	if params.ResponseSchema == nil {
+		return nil
+	}
+	return params.ResponseSchema.Raw
+}
+
+// request converts params into the wire format of the generateContent api
+func (params *ModelParams) request() (*generateContentRequest, error) {
+	request := &generateContentRequest{
+		Contents: make([]content, 0, len(params.Prompt)),
+		GenerationConfig: &generationConfig{
+			Temperature:     params.Temperature,
+			TopP:            params.TopP,
+			MaxOutputTokens: params.MaxOutputTokens,
+		},
+	}
+	if params.ResponseSchema != nil {
+		schema, err := responseSchema(params.ResponseSchema.Raw)
+		if err != nil {
+			return nil, err
+		}
+		request.GenerationConfig.ResponseMIMEType = "application/json"
+		request.GenerationConfig.ResponseSchema = schema
+	}
+	if params.System != "" {
+		request.SystemInstruction = &content{Parts: []part{{Text: params.System}}}
+	}
+	// gemini identifies function responses by the name of the function, the ids of the calls are our own
+	toolNames := map[string]string{}
+	for _, prompt := range params.Prompt {
+		c, err := prompt.content(toolNames)
+		if err != nil {
+			return nil, err
+		}
+		request.Contents = append(request.Contents, c)
+		for _, call := range prompt.ToolCalls {
+			toolNames[call.ID] = call.Name
+		}
+	}
+	return request, nil
+}
+
+// content converts prompt into a content, toolNames maps the ids of the previous tool calls to their function
+func (prompt Prompt) content(toolNames map[string]string) (content, error) {
+	if prompt.Role == Tool {
+		if prompt.ToolCallID == "" {
+			return content{}, errors.New("tool prompt requires tool_call_id")
+		}
+		name, ok := toolNames[prompt.ToolCallID]
+		if !ok {
+			return content{}, errors.Errorf("tool_call_id %s answers no tool call of the previous prompts", prompt.ToolCallID)
+		}
+		// the function response must be an object, plain results are wrapped
+		response := json.RawMessage(prompt.Content)
+		if !strings.HasPrefix(strings.TrimSpace(prompt.Content), "{") || !json.Valid(response) {
+			wrapped, err := json.Marshal(map[string]string{"content": prompt.Content})
+			if err != nil {
+				return content{}, err
+			}
+			response = wrapped
+		}
+		return content{
+			Role:  string(User),
+			Parts: []part{{FunctionResponse: &functionResponse{Name: name, Response: response}}},
+		}, nil
+	}
+	c := content{Role: string(prompt.Role)}
+	if prompt.Content != "" || len(prompt.ToolCalls) == 0 {
+		c.Parts = append(c.Parts, part{Text: prompt.Content})
+	}
+	for _, call := range prompt.ToolCalls {
+		args := json.RawMessage(lo.Ternary(call.Arguments == "", "{}", call.Arguments))
+		if !json.Valid(args) {
+			return content{}, errors.Errorf("arguments of tool call %s are not valid json", call.Name)
+		}
+		c.Parts = append(c.Parts, part{FunctionCall: &functionCall{Name: call.Name, Args: args}})
+	}
+	return c, nil
+}
+
+// tools converts the tools of the call options into the wire format of the generateContent api
+func tools(opts langchainllms.CallOptions) ([]tool, error) {
+	declarations := make([]functionDeclaration, 0, len(opts.Tools))
+	for _, t := range opts.Tools {
+		if t.Function == nil {
+			continue
+		}
+		parameters, err := llms.ToolParameters(t)
+		if err != nil {
+			return nil, errors.Errorf("encode parameters of tool %s: %v", t.Function.Name, err)
+		}
+		schema, err := responseSchema(parameters)
+		if err != nil {
+			return nil, errors.Errorf("translate parameters of tool %s: %v", t.Function.Name, err)
+		}
+		declarations = append(declarations, functionDeclaration{
+			Name:        t.Function.Name,
+			Description: t.Function.Description,
+			Parameters:  schema,
+		})
+	}
+	if len(declarations) == 0 {
+		return nil, nil
+	}
+	return []tool{{FunctionDeclarations: declarations}}, nil
+}
@@ -0,0 +1,119 @@
+package gemini
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+func TestPrependMessages(t *testing.T) {
+	tests := []struct {
+		name       string
+		system     string
+		messages   []llms.Message
+		wantSystem string
+		wantRoles  []Role
+		wantErr    bool
+	}{
+		{
+			name:      "assistant messages are model turns",
+			messages:  []llms.Message{{Role: llms.MessageRoleUser, Content: "Hi"}, {Role: llms.MessageRoleAssistant, Content: "Hello"}},
+			wantRoles: []Role{User, Model, User},
+		},
+		{
+			name:       "system messages join the system instruction",
+			system:     "Be brief.",
+			messages:   []llms.Message{{Role: llms.MessageRoleSystem, Content: "You are a guide."}},
+			wantSystem: "You are a guide.\n\nBe brief.",
+			wantRoles:  []Role{User},
+		},
+		{name: "unsupported role", messages: []llms.Message{{Role: "function", Content: "{}"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := DefaultModelParams()
+			params.System = tt.system
+			params.Prompt = []Prompt{{Role: User, Content: "Where is the colosseum?"}}
+			err := params.PrependMessages(tt.messages...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if params.System != tt.wantSystem {
+				t.Errorf("expected system %q, got %q", tt.wantSystem, params.System)
+			}
+			roles := make([]Role, 0, len(params.Prompt))
+			for _, prompt := range params.Prompt {
+				roles = append(roles, prompt.Role)
+			}
+			if !reflect.DeepEqual(roles, tt.wantRoles) {
+				t.Errorf("expected roles %v, got %v", tt.wantRoles, roles)
+			}
+		})
+	}
+}
+
+func TestPromptContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  Prompt
+		want    string
+		wantErr bool
+	}{
+		{name: "text", prompt: Prompt{Role: User, Content: "Hi"}, want: `{"role":"user","parts":[{"text":"Hi"}]}`},
+		{
+			name:   "function call",
+			prompt: Prompt{Role: Model, ToolCalls: []llms.ToolCall{{Name: "get_weather", Arguments: `{"city":"Paris"}`}}},
+			want:   `{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}}]}`,
+		},
+		{
+			name:   "function call without arguments",
+			prompt: Prompt{Role: Model, ToolCalls: []llms.ToolCall{{Name: "now"}}},
+			want:   `{"role":"model","parts":[{"functionCall":{"name":"now","args":{}}}]}`,
+		},
+		{
+			name:   "object function response",
+			prompt: Prompt{Role: Tool, ToolCallID: "call_1", Content: `{"sky":"sunny"}`},
+			want:   `{"role":"user","parts":[{"functionResponse":{"name":"get_weather","response":{"sky":"sunny"}}}]}`,
+		},
+		{
+			name:   "plain function response is wrapped",
+			prompt: Prompt{Role: Tool, ToolCallID: "call_1", Content: "sunny"},
+			want:   `{"role":"user","parts":[{"functionResponse":{"name":"get_weather","response":{"content":"sunny"}}}]}`,
+		},
+		{
+			name:   "list function response is wrapped",
+			prompt: Prompt{Role: Tool, ToolCallID: "call_1", Content: `["sunny"]`},
+			want:   `{"role":"user","parts":[{"functionResponse":{"name":"get_weather","response":{"content":"[\"sunny\"]"}}}]}`,
+		},
+		{name: "function response without call id", prompt: Prompt{Role: Tool, Content: "sunny"}, wantErr: true},
+		{name: "function response to an unknown call", prompt: Prompt{Role: Tool, ToolCallID: "call_2", Content: "sunny"}, wantErr: true},
+		{
+			name:    "invalid function call arguments",
+			prompt:  Prompt{Role: Model, ToolCalls: []llms.ToolCall{{Name: "get_weather", Arguments: `{"city":`}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := tt.prompt.content(map[string]string{"call_1": "get_weather"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := json.Marshal(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
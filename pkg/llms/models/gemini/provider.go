@@ -0,0 +1,19 @@
+package gemini
+
+import (
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+func init() {
+	llms.MustRegister(llms.Provider{
+		Type: llms.Gemini,
+		New: func(opts llms.ClientOptions) (llms.LLM, error) {
+			return NewGemini(opts)
+		},
+		DefaultModels: llms.GeminiModels,
+		NewParams: func() llms.ModelParams {
+			params := DefaultModelParams()
+			return &params
+		},
+	})
+}
@@ -0,0 +1,149 @@
+package gemini
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+type Response struct {
+	Code         int        `json:"code"`
+	Data         string     `json:"data"`
+	Msg          string     `json:"msg"`
+	Success      bool       `json:"success"`
+	FinishReason string     `json:"finish_reason,omitempty"`
+	Usage        llms.Usage `json:"usage"`
+	// ToolCalls requested by the model
+	ToolCalls []llms.ToolCall `json:"tool_calls,omitempty"`
+}
+
+func (response *Response) Type() llms.LLMType {
+	return llms.Gemini
+}
+
+func (response *Response) Bytes() []byte {
+	bytes, err := json.Marshal(response)
+	if err != nil {
+		return []byte{}
+	}
+	return bytes
+}
+
+func (response *Response) String() string {
+	return string(response.Bytes())
+}
+
+func (response *Response) Unmarshal(bytes []byte) error {
+	return json.Unmarshal(bytes, response)
+}
+
+func (response *Response) TokenUsage() llms.Usage {
+	return response.Usage
+}
+
+func (response *Response) GetData() string {
+	return response.Data
+}
+
+func (response *Response) GetToolCalls() []llms.ToolCall {
+	return response.ToolCalls
+}
+
+// generateContentRequest is the request body of POST /models/{model}:generateContent
+type generateContentRequest struct {
+	Contents          []content         `json:"contents"`
+	SystemInstruction *content          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *generationConfig `json:"generationConfig,omitempty"`
+	Tools             []tool            `json:"tools,omitempty"`
+}
+
+type content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []part `json:"parts"`
+}
+
+type part struct {
+	Text             string            `json:"text,omitempty"`
+	FunctionCall     *functionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *functionResponse `json:"functionResponse,omitempty"`
+}
+
+type functionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type functionResponse struct {
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+type tool struct {
+	FunctionDeclarations []functionDeclaration `json:"functionDeclarations"`
+}
+
+type functionDeclaration struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+type generationConfig struct {
+	Temperature      float32         `json:"temperature,omitempty"`
+	TopP             float32         `json:"topP,omitempty"`
+	MaxOutputTokens  int             `json:"maxOutputTokens,omitempty"`
+	ResponseMIMEType string          `json:"responseMimeType,omitempty"`
+	ResponseSchema   json.RawMessage `json:"responseSchema,omitempty"`
+}
+
+// generateContentResponse is the response body of POST /models/{model}:generateContent
+type generateContentResponse struct {
+	Candidates    []candidate   `json:"candidates"`
+	UsageMetadata usageMetadata `json:"usageMetadata"`
+}
+
+type candidate struct {
+	Content      content `json:"content"`
+	FinishReason string  `json:"finishReason"`
+}
+
+type usageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+func (resp *generateContentResponse) toResponse() *Response {
+	var text strings.Builder
+	var toolCalls []llms.ToolCall
+	for _, p := range resp.Candidates[0].Content.Parts {
+		text.WriteString(p.Text)
+		if p.FunctionCall != nil {
+			toolCalls = append(toolCalls, llms.ToolCall{
+				ID:        toolCallID(),
+				Name:      p.FunctionCall.Name,
+				Arguments: string(p.FunctionCall.Args),
+			})
+		}
+	}
+	return &Response{
+		Code:         200,
+		Data:         text.String(),
+		Msg:          "call gemini model success",
+		Success:      true,
+		FinishReason: resp.Candidates[0].FinishReason,
+		Usage: llms.Usage{
+			PromptTokens:     resp.UsageMetadata.PromptTokenCount,
+			CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
+			TotalTokens:      resp.UsageMetadata.TotalTokenCount,
+		},
+		ToolCalls: toolCalls,
+	}
+}
+
+// toolCallID returns a unique id for a function call, gemini does not identify the calls it requests
+func toolCallID() string {
+	return "call_" + rand.String(16)
+}
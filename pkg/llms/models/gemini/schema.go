@@ -0,0 +1,171 @@
+package gemini
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// schemaKeywords are the keywords of the openapi schema subset gemini accepts besides
+// properties, items and anyOf, which hold schemas themselves
+var schemaKeywords = map[string]bool{
+	"type":             true,
+	"format":           true,
+	"title":            true,
+	"description":      true,
+	"nullable":         true,
+	"enum":             true,
+	"required":         true,
+	"minItems":         true,
+	"maxItems":         true,
+	"minProperties":    true,
+	"maxProperties":    true,
+	"minLength":        true,
+	"maxLength":        true,
+	"pattern":          true,
+	"minimum":          true,
+	"maximum":          true,
+	"example":          true,
+	"default":          true,
+	"propertyOrdering": true,
+}
+
+// maxSchemaDepth bounds the nesting of the response schema, recursive $refs can not be inlined
+const maxSchemaDepth = 32
+
+// responseSchema translates a json schema into the openapi subset gemini accepts as response schema
+// and as parameters of function declarations.
+// Local $refs are inlined, a type list with null becomes nullable, const becomes enum,
+// and keywords gemini does not know, like additionalProperties or $schema, are dropped.
+func responseSchema(raw []byte) (json.RawMessage, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return nil, errors.Errorf("invalid json schema: %v", err)
+	}
+	schema, err := translateSchema(root, root, 0)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(schema)
+}
+
+func translateSchema(root, schema map[string]interface{}, depth int) (map[string]interface{}, error) {
+	if depth > maxSchemaDepth {
+		return nil, errors.New("response schema is nested too deep, recursive schemas are not supported by gemini")
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := resolveRef(root, ref)
+		if err != nil {
+			return nil, err
+		}
+		// keywords next to $ref, like a description, take precedence over the referenced schema
+		merged := make(map[string]interface{}, len(target)+len(schema))
+		for key, value := range target {
+			merged[key] = value
+		}
+		for key, value := range schema {
+			if key != "$ref" {
+				merged[key] = value
+			}
+		}
+		return translateSchema(root, merged, depth+1)
+	}
+
+	translated := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "type":
+			types, ok := value.([]interface{})
+			if !ok {
+				translated[key] = value
+				continue
+			}
+			// a type list like ["string", "null"] is a nullable type
+			var nonNull []interface{}
+			for _, t := range types {
+				if t == "null" {
+					translated["nullable"] = true
+				} else {
+					nonNull = append(nonNull, t)
+				}
+			}
+			if len(nonNull) != 1 {
+				return nil, errors.Errorf("response schema type %v is not supported by gemini, use anyOf", types)
+			}
+			translated[key] = nonNull[0]
+		case "const":
+			translated["enum"] = []interface{}{value}
+		case "properties":
+			properties, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, errors.New("properties of the response schema must be an object")
+			}
+			result := make(map[string]interface{}, len(properties))
+			for name, property := range properties {
+				s, err := subSchema(root, property, depth)
+				if err != nil {
+					return nil, errors.Wrapf(err, "property %s", name)
+				}
+				result[name] = s
+			}
+			translated[key] = result
+		case "items":
+			s, err := subSchema(root, value, depth)
+			if err != nil {
+				return nil, errors.Wrap(err, "items")
+			}
+			translated[key] = s
+		case "anyOf":
+			schemas, ok := value.([]interface{})
+			if !ok {
+				return nil, errors.New("anyOf of the response schema must be a list")
+			}
+			result := make([]interface{}, 0, len(schemas))
+			for _, item := range schemas {
+				s, err := subSchema(root, item, depth)
+				if err != nil {
+					return nil, errors.Wrap(err, "anyOf")
+				}
+				result = append(result, s)
+			}
+			translated[key] = result
+		default:
+			if schemaKeywords[key] {
+				translated[key] = value
+			}
+		}
+	}
+	return translated, nil
+}
+
+func subSchema(root map[string]interface{}, value interface{}, depth int) (map[string]interface{}, error) {
+	schema, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("schema must be an object, got %T", value)
+	}
+	return translateSchema(root, schema, depth+1)
+}
+
+// resolveRef returns the schema a local json pointer like #/$defs/item points to
+func resolveRef(root map[string]interface{}, ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, errors.Errorf("response schema $ref %s is not supported by gemini, only local refs are inlined", ref)
+	}
+	var node interface{} = root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("response schema $ref %s not found", ref)
+		}
+		if node, ok = object[token]; !ok {
+			return nil, errors.Errorf("response schema $ref %s not found", ref)
+		}
+	}
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("response schema $ref %s is not a schema", ref)
+	}
+	return schema, nil
+}
@@ -0,0 +1,90 @@
+package gemini
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestResponseSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "supported keywords are kept",
+			schema: `{"type":"object","description":"a city","properties":{"name":{"type":"string","maxLength":64}},"required":["name"]}`,
+			want:   `{"type":"object","description":"a city","properties":{"name":{"type":"string","maxLength":64}},"required":["name"]}`,
+		},
+		{
+			name: "unsupported keywords are dropped",
+			schema: `{"$schema":"https://json-schema.org/draft/2020-12/schema","$id":"city","type":"object",
+				"properties":{"tags":{"type":"array","items":{"type":"string","additionalProperties":false},"uniqueItems":true}},
+				"additionalProperties":false}`,
+			want: `{"type":"object","properties":{"tags":{"type":"array","items":{"type":"string"}}}}`,
+		},
+		{
+			name: "local refs are inlined",
+			schema: `{"type":"object","properties":{"home":{"$ref":"#/$defs/city"},"work":{"$ref":"#/$defs/city","description":"office"}},
+				"$defs":{"city":{"type":"object","description":"a city","properties":{"name":{"type":"string"}}}}}`,
+			want: `{"type":"object","properties":{
+				"home":{"type":"object","description":"a city","properties":{"name":{"type":"string"}}},
+				"work":{"type":"object","description":"office","properties":{"name":{"type":"string"}}}}}`,
+		},
+		{
+			name:   "refs of definitions",
+			schema: `{"type":"array","items":{"$ref":"#/definitions/name"},"definitions":{"name":{"type":"string"}}}`,
+			want:   `{"type":"array","items":{"type":"string"}}`,
+		},
+		{
+			name:   "nullable type list",
+			schema: `{"type":"object","properties":{"age":{"type":["integer","null"]}}}`,
+			want:   `{"type":"object","properties":{"age":{"type":"integer","nullable":true}}}`,
+		},
+		{
+			name:   "const",
+			schema: `{"type":"string","const":"paris"}`,
+			want:   `{"type":"string","enum":["paris"]}`,
+		},
+		{
+			name:   "any of",
+			schema: `{"anyOf":[{"type":"string","additionalProperties":false},{"type":"integer"}]}`,
+			want:   `{"anyOf":[{"type":"string"},{"type":"integer"}]}`,
+		},
+		{name: "several types", schema: `{"type":["string","integer"]}`, wantErr: true},
+		{name: "remote ref", schema: `{"$ref":"https://example.com/city.json"}`, wantErr: true},
+		{name: "missing ref", schema: `{"$ref":"#/$defs/city"}`, wantErr: true},
+		{
+			name:    "recursive ref",
+			schema:  `{"$ref":"#/$defs/node","$defs":{"node":{"type":"object","properties":{"next":{"$ref":"#/$defs/node"}}}}}`,
+			wantErr: true,
+		},
+		{name: "invalid json", schema: `{"type":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := responseSchema([]byte(tt.schema))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotSchema, wantSchema interface{}
+			if err := json.Unmarshal(got, &gotSchema); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &wantSchema); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotSchema, wantSchema) {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package openai
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/fleezesd/llm-operator/pkg/llms"
@@ -27,20 +28,29 @@ func BuildAPIURL(model string, method Method) string {
 	return fmt.Sprintf("%s/%s/%s", OpenAIModelAPIURL, model, method)
 }
 
-var _ llms.LLM = (*OpenAI)(nil)
+var (
+	_ llms.LLM         = (*OpenAI)(nil)
+	_ llms.ModelLister = (*OpenAI)(nil)
+)
 
 type OpenAI struct {
-	apiKey  string
-	baseURL string
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
 }
 
-func NewOpenAI(apiKey, baseURL string) (*OpenAI, error) {
-	if apiKey == "" {
-		return nil, errors.New("API key cannot be empty")
+func NewOpenAI(opts llms.ClientOptions) (*OpenAI, error) {
+	if !opts.HasCredentials() {
+		return nil, errors.New("credentials cannot be empty")
+	}
+	httpClient, err := opts.HTTPClient(OpenAIDefaultTimeout)
+	if err != nil {
+		return nil, err
 	}
 	client := &OpenAI{
-		apiKey:  apiKey,
-		baseURL: lo.Ternary(baseURL == "", OpenAIModelAPIURL, baseURL),
+		apiKey:     opts.APIKey,
+		baseURL:    lo.Ternary(opts.BaseURL == "", OpenAIModelAPIURL, opts.BaseURL),
+		httpClient: httpClient,
 	}
 	return client, nil
 }
@@ -49,48 +59,42 @@ func (o *OpenAI) Type() llms.LLMType {
 	return llms.OpenAI
 }
 
-func (o *OpenAI) Call(input []byte) (llms.Response, error) {
-	ctx := context.Background()
-	// default use gpt-3.5 turbo
-	llm, err := langchainopenai.New(
-		langchainopenai.WithBaseURL(o.baseURL),
-		langchainopenai.WithToken(o.apiKey),
-	)
+func (o *OpenAI) Call(ctx context.Context, input []byte, options ...langchainllms.CallOption) (llms.Response, error) {
+	params := DefaultModelParams()
+	if err := params.Unmarshal(input); err != nil {
+		return nil, errors.Errorf("decode openai params: %v", err)
+	}
+	messages, err := params.MessageContents()
 	if err != nil {
-		return nil, errors.Errorf("init openai client: %v", err)
+		return nil, err
 	}
 
-	resp, err := llm.Call(ctx, string(input))
+	options = append(params.CallOptions(), options...)
+	var format *langchainopenai.ResponseFormat
+	if params.ResponseSchema != nil {
+		if format, err = ResponseFormat(params.ResponseSchema.Raw); err != nil {
+			// the output is still validated against the schema, the api is only asked for a json object
+			format, options = nil, append(options, langchainllms.WithJSONMode())
+		}
+	}
+	choice, err := ChatCompletion(ctx, o.httpClient, o.apiKey, o.baseURL, messages, format, options...)
 	if err != nil {
 		return nil, err
 	}
-
-	return &Response{
-		Code:    200,
-		Data:    resp,
-		Msg:     "call openai model success",
-		Success: true,
-	}, nil
+	return newResponse(choice), nil
 }
 
 func (o *OpenAI) Validate(ctx context.Context, options ...langchainllms.CallOption) (llms.Response, error) {
-	llm, err := langchainopenai.New(
-		langchainopenai.WithBaseURL(o.baseURL),
-		langchainopenai.WithToken(o.apiKey),
-	)
-	if err != nil {
-		return nil, errors.Errorf("init openai client: %d", err)
+	messages := []langchainllms.MessageContent{
+		langchainllms.TextParts(langchainllms.ChatMessageTypeHuman, "Hello"),
 	}
-
-	resp, err := llm.Call(ctx, "Hello", options...)
+	choice, err := ChatCompletion(ctx, o.httpClient, o.apiKey, o.baseURL, messages, nil, options...)
 	if err != nil {
 		return nil, err
 	}
+	return newResponse(choice), nil
+}
 
-	return &Response{
-		Code:    200,
-		Data:    resp,
-		Msg:     "call openai model success",
-		Success: true,
-	}, nil
+func (o *OpenAI) ListModels(ctx context.Context) ([]string, error) {
+	return ListModels(ctx, o.httpClient, o.apiKey, o.baseURL)
 }
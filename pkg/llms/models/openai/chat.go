@@ -0,0 +1,140 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/pkg/errors"
+	langchainllms "github.com/tmc/langchaingo/llms"
+	langchainopenai "github.com/tmc/langchaingo/llms/openai"
+)
+
+// ChatCompletion sends messages to an openai compatible chat completions api
+// with httpClient and returns the first choice. It is shared by providers speaking the openai wire format.
+// A non nil format constrains the output to json matching its schema.
+func ChatCompletion(ctx context.Context, httpClient *http.Client, apiKey, baseURL string, messages []langchainllms.MessageContent,
+	format *langchainopenai.ResponseFormat, options ...langchainllms.CallOption) (*langchainllms.ContentChoice, error) {
+	doer := &chatDoer{doer: httpClient}
+	if apiKey == "" {
+		// the client is authenticated by other means, but langchaingo insists on a token
+		apiKey, doer.noAuthorization = placeholderToken, true
+	}
+	clientOptions := []langchainopenai.Option{
+		langchainopenai.WithBaseURL(baseURL),
+		langchainopenai.WithToken(apiKey),
+		langchainopenai.WithHTTPClient(doer),
+	}
+	if format != nil {
+		clientOptions = append(clientOptions, langchainopenai.WithResponseFormat(format))
+	}
+	llm, err := langchainopenai.New(clientOptions...)
+	if err != nil {
+		return nil, errors.Errorf("init openai client: %v", err)
+	}
+
+	resp, err := llm.GenerateContent(ctx, messages, options...)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("chat completion returned no choices")
+	}
+	return resp.Choices[0], nil
+}
+
+// UsageFromChoice reads the token usage langchaingo puts into the generation info
+func UsageFromChoice(choice *langchainllms.ContentChoice) llms.Usage {
+	usage := llms.Usage{}
+	if choice == nil {
+		return usage
+	}
+	usage.PromptTokens, _ = choice.GenerationInfo["PromptTokens"].(int)
+	usage.CompletionTokens, _ = choice.GenerationInfo["CompletionTokens"].(int)
+	usage.TotalTokens, _ = choice.GenerationInfo["TotalTokens"].(int)
+	return usage
+}
+
+// ChatMessage builds a chat message of role.
+// Tool messages answer toolCallID, assistant messages carry the tool calls they requested.
+func ChatMessage(role langchainllms.ChatMessageType, content, toolCallID string, toolCalls []llms.ToolCall) langchainllms.MessageContent {
+	if role == langchainllms.ChatMessageTypeTool {
+		return langchainllms.MessageContent{
+			Role:  role,
+			Parts: []langchainllms.ContentPart{langchainllms.ToolCallResponse{ToolCallID: toolCallID, Content: content}},
+		}
+	}
+	message := langchainllms.MessageContent{Role: role}
+	if content != "" || len(toolCalls) == 0 {
+		message.Parts = append(message.Parts, langchainllms.TextContent{Text: content})
+	}
+	for _, call := range toolCalls {
+		message.Parts = append(message.Parts, langchainllms.ToolCall{
+			ID:           call.ID,
+			Type:         "function",
+			FunctionCall: &langchainllms.FunctionCall{Name: call.Name, Arguments: call.Arguments},
+		})
+	}
+	return message
+}
+
+// ToolCallsFromChoice returns the tool calls requested in choice
+func ToolCallsFromChoice(choice *langchainllms.ContentChoice) []llms.ToolCall {
+	if choice == nil || len(choice.ToolCalls) == 0 {
+		return nil
+	}
+	calls := make([]llms.ToolCall, 0, len(choice.ToolCalls))
+	for _, call := range choice.ToolCalls {
+		if call.FunctionCall == nil {
+			continue
+		}
+		calls = append(calls, llms.ToolCall{ID: call.ID, Name: call.FunctionCall.Name, Arguments: call.FunctionCall.Arguments})
+	}
+	return calls
+}
+
+// Float64 converts float32 params without the binary noise of a plain conversion(0.8 -> 0.800000011920929)
+func Float64(f float32) float64 {
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(float64(f), 'f', -1, 32), 64)
+	return v
+}
+
+// ResponseFormat returns the json_schema response format constraining the output to schema.
+// It fails for schemas which the schema types of langchaingo can not express.
+func ResponseFormat(schema json.RawMessage) (*langchainopenai.ResponseFormat, error) {
+	property := &langchainopenai.ResponseFormatJSONSchemaProperty{}
+	if err := json.Unmarshal(schema, property); err != nil {
+		return nil, err
+	}
+	return &langchainopenai.ResponseFormat{
+		Type:       "json_schema",
+		JSONSchema: &langchainopenai.ResponseFormatJSONSchema{Name: "response", Schema: property},
+	}, nil
+}
+
+// placeholderToken is passed to langchaingo when there is no api key, it is never sent
+const placeholderToken = "none"
+
+// chatDoer turns failed chat completion responses into llms.HTTPError, so callers can tell retryable errors apart
+type chatDoer struct {
+	doer *http.Client
+	// noAuthorization drops the authorization header langchaingo sets from the placeholder token
+	noAuthorization bool
+}
+
+func (d *chatDoer) Do(req *http.Request) (*http.Response, error) {
+	if d.noAuthorization {
+		req.Header.Del("Authorization")
+	}
+	resp, err := d.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, llms.NewHTTPError("chat completion", resp)
+	}
+	return resp, nil
+}
@@ -4,16 +4,25 @@ import (
 	"encoding/json"
 
 	"github.com/fleezesd/llm-operator/pkg/llms"
+	"github.com/pkg/errors"
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type Role string
 
 const (
+	System    Role = "system"
 	User      Role = "user"
 	Assistant Role = "assistant"
+	// Tool messages carry the result of a tool call requested by the assistant
+	Tool Role = "tool"
 )
 
-var _ llms.ModelParams = (*ModelParams)(nil)
+var (
+	_ llms.ModelParams            = (*ModelParams)(nil)
+	_ llms.StructuredOutputParams = (*ModelParams)(nil)
+)
 
 // +kubebuilder:object:generate=true
 type ModelParams struct {
@@ -37,11 +46,22 @@ type ModelParams struct {
 
 	// Incremental is only Used for SSE Invoke
 	Incremental bool `json:"incremental,omitempty"`
+
+	// ResponseSchema constrains the output to json matching this json schema.
+	// The output is validated against it, spec.responseSchema of the prompt replaces it.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	ResponseSchema *runtime.RawExtension `json:"response_schema,omitempty"`
 }
 
+// +kubebuilder:object:generate=true
 type Prompt struct {
 	Role    Role   `json:"role,omitempty"`
 	Content string `json:"content,omitempty"`
+	// ToolCallID is the id of the tool call a tool message answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// ToolCalls requested by an assistant message
+	ToolCalls []llms.ToolCall `json:"tool_calls,omitempty"`
 }
 
 func DefaultModelParams() ModelParams {
@@ -65,3 +85,65 @@ func (params *ModelParams) Marshal() []byte {
 func (params *ModelParams) Unmarshal(bytes []byte) error {
 	return json.Unmarshal(bytes, params)
 }
+
+func (params *ModelParams) IsIncremental() bool {
+	return params.Incremental
+}
+
+func (params *ModelParams) GetModel() string {
+	return params.Model
+}
+
+func (params *ModelParams) PrependMessages(messages ...llms.Message) error {
+	prompts := make([]Prompt, 0, len(messages)+len(params.Prompt))
+	for _, message := range messages {
+		prompts = append(prompts, Prompt{Role: Role(message.Role), Content: message.Content})
+	}
+	params.Prompt = append(prompts, params.Prompt...)
+	return nil
+}
+
+func (params *ModelParams) SetResponseSchema(schema []byte) {
+	params.ResponseSchema = &runtime.RawExtension{Raw: schema}
+}
+
+func (params *ModelParams) GetResponseSchema() []byte {
+	if params.ResponseSchema == nil {
+		return nil
+	}
+	return params.ResponseSchema.Raw
+}
+
+// MessageContents converts the prompts into chat messages
+func (params *ModelParams) MessageContents() ([]langchainllms.MessageContent, error) {
+	messages := make([]langchainllms.MessageContent, 0, len(params.Prompt))
+	for _, prompt := range params.Prompt {
+		var role langchainllms.ChatMessageType
+		switch prompt.Role {
+		case System:
+			role = langchainllms.ChatMessageTypeSystem
+		case User, "":
+			role = langchainllms.ChatMessageTypeHuman
+		case Assistant:
+			role = langchainllms.ChatMessageTypeAI
+		case Tool:
+			if prompt.ToolCallID == "" {
+				return nil, errors.New("tool prompt requires tool_call_id")
+			}
+			role = langchainllms.ChatMessageTypeTool
+		default:
+			return nil, errors.Errorf("unsupported prompt role %q", prompt.Role)
+		}
+		messages = append(messages, ChatMessage(role, prompt.Content, prompt.ToolCallID, prompt.ToolCalls))
+	}
+	return messages, nil
+}
+
+// CallOptions converts the params into chat completion options
+func (params *ModelParams) CallOptions() []langchainllms.CallOption {
+	return []langchainllms.CallOption{
+		langchainllms.WithModel(params.Model),
+		langchainllms.WithTemperature(Float64(params.Temperature)),
+		langchainllms.WithTopP(Float64(params.TopP)),
+	}
+}
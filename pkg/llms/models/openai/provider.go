@@ -0,0 +1,19 @@
+package openai
+
+import (
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+func init() {
+	llms.MustRegister(llms.Provider{
+		Type: llms.OpenAI,
+		New: func(opts llms.ClientOptions) (llms.LLM, error) {
+			return NewOpenAI(opts)
+		},
+		DefaultModels: llms.OpenAIModels,
+		NewParams: func() llms.ModelParams {
+			params := DefaultModelParams()
+			return &params
+		},
+	})
+}
@@ -4,13 +4,30 @@ import (
 	"encoding/json"
 
 	"github.com/fleezesd/llm-operator/pkg/llms"
+	langchainllms "github.com/tmc/langchaingo/llms"
 )
 
 type Response struct {
-	Code    int    `json:"code"`
-	Data    string `json:"data"`
-	Msg     string `json:"msg"`
-	Success bool   `json:"success"`
+	Code         int        `json:"code"`
+	Data         string     `json:"data"`
+	Msg          string     `json:"msg"`
+	Success      bool       `json:"success"`
+	FinishReason string     `json:"finish_reason,omitempty"`
+	Usage        llms.Usage `json:"usage"`
+	// ToolCalls requested by the model
+	ToolCalls []llms.ToolCall `json:"tool_calls,omitempty"`
+}
+
+func newResponse(choice *langchainllms.ContentChoice) *Response {
+	return &Response{
+		Code:         200,
+		Data:         choice.Content,
+		Msg:          "call openai model success",
+		Success:      true,
+		FinishReason: choice.StopReason,
+		Usage:        UsageFromChoice(choice),
+		ToolCalls:    ToolCallsFromChoice(choice),
+	}
 }
 
 func (response *Response) Type() llms.LLMType {
@@ -32,3 +49,15 @@ func (response *Response) String() string {
 func (response *Response) Unmarshal(bytes []byte) error {
 	return json.Unmarshal(bytes, response)
 }
+
+func (response *Response) TokenUsage() llms.Usage {
+	return response.Usage
+}
+
+func (response *Response) GetData() string {
+	return response.Data
+}
+
+func (response *Response) GetToolCalls() []llms.ToolCall {
+	return response.ToolCalls
+}
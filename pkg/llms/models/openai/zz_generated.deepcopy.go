@@ -20,7 +20,10 @@ limitations under the License.
 
 package openai
 
-import ()
+import (
+	"github.com/fleezesd/llm-operator/pkg/llms"
+	"k8s.io/apimachinery/pkg/runtime"
+)
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModelParams) DeepCopyInto(out *ModelParams) {
@@ -28,7 +31,14 @@ func (in *ModelParams) DeepCopyInto(out *ModelParams) {
 	if in.Prompt != nil {
 		in, out := &in.Prompt, &out.Prompt
 		*out = make([]Prompt, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResponseSchema != nil {
+		in, out := &in.ResponseSchema, &out.ResponseSchema
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -41,3 +51,23 @@ func (in *ModelParams) DeepCopy() *ModelParams {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Prompt) DeepCopyInto(out *Prompt) {
+	*out = *in
+	if in.ToolCalls != nil {
+		in, out := &in.ToolCalls, &out.ToolCalls
+		*out = make([]llms.ToolCall, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Prompt.
+func (in *Prompt) DeepCopy() *Prompt {
+	if in == nil {
+		return nil
+	}
+	out := new(Prompt)
+	in.DeepCopyInto(out)
+	return out
+}
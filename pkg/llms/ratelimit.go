@@ -0,0 +1,134 @@
+package llms
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// concurrencyRetryInterval is the wait time suggested when all concurrent call slots are taken
+const concurrencyRetryInterval = 3 * time.Second
+
+// RateLimits of the calls to a llm, a zero value disables the limit
+type RateLimits struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+	MaxConcurrency    int
+}
+
+// Throttled is returned by Limiters.Acquire when a call would exceed a rate limit
+type Throttled struct {
+	// Limit which is exceeded
+	Limit string
+	// RetryAfter is the wait time until the call may be allowed
+	RetryAfter time.Duration
+}
+
+func (t *Throttled) Error() string {
+	return fmt.Sprintf("%s limit reached, retry after %s", t.Limit, t.RetryAfter.Round(time.Second))
+}
+
+// Limiters enforces the rate limits of llms, keyed by llm.
+// They are shared by all calls inside the operator.
+type Limiters struct {
+	mu       sync.Mutex
+	limiters map[string]*limiter
+}
+
+type limiter struct {
+	limits   RateLimits
+	requests *rate.Limiter
+	tokens   *rate.Limiter
+	inflight int
+}
+
+func NewLimiters() *Limiters {
+	return &Limiters{limiters: make(map[string]*limiter)}
+}
+
+// Forget drops the limiter of the llm identified by key once it is deleted
+func (l *Limiters) Forget(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.limiters, key)
+}
+
+// Acquire takes a call slot of the llm identified by key.
+// The returned release function must be called with the usage of the call once it finished.
+// If a limit is reached, no slot is taken and a *Throttled error is returned.
+func (l *Limiters) Acquire(key string, limits RateLimits) (func(Usage), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limits == (RateLimits{}) {
+		// nothing to enforce, a limiter left from former limits is dropped
+		delete(l.limiters, key)
+		return func(Usage) {}, nil
+	}
+	lim, ok := l.limiters[key]
+	if !ok || lim.limits != limits {
+		// limits changed, start over with full buckets but keep counting the calls in flight
+		inflight := 0
+		if ok {
+			inflight = lim.inflight
+		}
+		lim = newLimiter(limits, inflight)
+		l.limiters[key] = lim
+	}
+
+	if limits.MaxConcurrency > 0 && lim.inflight >= limits.MaxConcurrency {
+		return nil, &Throttled{Limit: "max concurrency", RetryAfter: concurrencyRetryInterval}
+	}
+	now := time.Now()
+	// tokens are charged once the call finished, so a call is allowed as long as the bucket is not in debt
+	if lim.tokens != nil {
+		if available := lim.tokens.TokensAt(now); available < 1 {
+			wait := time.Duration((1 - available) / float64(lim.tokens.Limit()) * float64(time.Second))
+			return nil, &Throttled{Limit: "tokens per minute", RetryAfter: wait}
+		}
+	}
+	if lim.requests != nil {
+		reservation := lim.requests.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			return nil, &Throttled{Limit: "requests per minute", RetryAfter: delay}
+		}
+	}
+
+	lim.inflight++
+	released := false
+	return func(usage Usage) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if released {
+			return
+		}
+		released = true
+		// the limiter may have been replaced by a limits change or dropped during the call
+		current, ok := l.limiters[key]
+		if !ok {
+			return
+		}
+		current.inflight = max(current.inflight-1, 0)
+		if current.tokens != nil && usage.TotalTokens > 0 {
+			current.tokens.ReserveN(time.Now(), min(usage.TotalTokens, current.tokens.Burst()))
+		}
+	}, nil
+}
+
+func newLimiter(limits RateLimits, inflight int) *limiter {
+	lim := &limiter{limits: limits, inflight: inflight}
+	if limits.RequestsPerMinute > 0 {
+		lim.requests = rate.NewLimiter(perMinute(limits.RequestsPerMinute), limits.RequestsPerMinute)
+	}
+	if limits.TokensPerMinute > 0 {
+		lim.tokens = rate.NewLimiter(perMinute(limits.TokensPerMinute), limits.TokensPerMinute)
+	}
+	return lim
+}
+
+func perMinute(n int) rate.Limit {
+	return rate.Limit(float64(n) / time.Minute.Seconds())
+}
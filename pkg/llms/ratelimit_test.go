@@ -0,0 +1,132 @@
+package llms
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLimitersAcquire(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits RateLimits
+		// calls are acquired in order, usage is released right away unless hold is set
+		calls   int
+		usage   Usage
+		hold    bool
+		allowed int
+		limit   string
+	}{
+		{name: "no limits", calls: 10, allowed: 10},
+		{name: "requests per minute", limits: RateLimits{RequestsPerMinute: 3}, calls: 5, allowed: 3, limit: "requests per minute"},
+		{name: "max concurrency", limits: RateLimits{MaxConcurrency: 2}, calls: 3, hold: true, allowed: 2, limit: "max concurrency"},
+		{name: "released calls free their slot", limits: RateLimits{MaxConcurrency: 2}, calls: 5, allowed: 5},
+		{
+			name:    "tokens per minute",
+			limits:  RateLimits{TokensPerMinute: 100},
+			calls:   3,
+			usage:   Usage{TotalTokens: 100},
+			allowed: 1,
+			limit:   "tokens per minute",
+		},
+		{name: "tokens below the limit", limits: RateLimits{TokensPerMinute: 100}, calls: 3, usage: Usage{TotalTokens: 10}, allowed: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiters := NewLimiters()
+			allowed := 0
+			var throttled *Throttled
+			for i := 0; i < tt.calls; i++ {
+				release, err := limiters.Acquire("default/llm", tt.limits)
+				if err != nil {
+					if !errors.As(err, &throttled) {
+						t.Fatalf("expected a throttled error, got %v", err)
+					}
+					break
+				}
+				allowed++
+				if !tt.hold {
+					release(tt.usage)
+				}
+			}
+			if allowed != tt.allowed {
+				t.Errorf("expected %d allowed calls, got %d", tt.allowed, allowed)
+			}
+			switch {
+			case tt.limit == "" && throttled != nil:
+				t.Errorf("unexpected throttling: %v", throttled)
+			case tt.limit != "" && throttled == nil:
+				t.Errorf("expected the %s limit to be reached", tt.limit)
+			case throttled != nil && (throttled.Limit != tt.limit || throttled.RetryAfter <= 0):
+				t.Errorf("expected the %s limit with a wait time, got %v", tt.limit, throttled)
+			}
+		})
+	}
+}
+
+func TestLimitersReleaseOnce(t *testing.T) {
+	limiters := NewLimiters()
+	limits := RateLimits{MaxConcurrency: 1}
+	release, err := limiters.Acquire("default/llm", limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := limiters.Acquire("default/other", limits)
+	if err != nil {
+		t.Fatalf("llms are limited separately: %v", err)
+	}
+	defer other(Usage{})
+	release(Usage{})
+	release(Usage{})
+	if _, err := limiters.Acquire("default/llm", limits); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limiters.Acquire("default/llm", limits); err == nil {
+		t.Error("expected a second release to free no slot")
+	}
+}
+
+func TestLimitersLimitsChange(t *testing.T) {
+	limiters := NewLimiters()
+	if _, err := limiters.Acquire("default/llm", RateLimits{RequestsPerMinute: 1, MaxConcurrency: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limiters.Acquire("default/llm", RateLimits{RequestsPerMinute: 1, MaxConcurrency: 2}); err == nil {
+		t.Fatal("expected the requests per minute limit to be reached")
+	}
+	// new limits start with full buckets, but the call in flight still counts
+	release, err := limiters.Acquire("default/llm", RateLimits{RequestsPerMinute: 2, MaxConcurrency: 2})
+	if err != nil {
+		t.Fatalf("expected a full bucket after the limits changed: %v", err)
+	}
+	defer release(Usage{})
+	var throttled *Throttled
+	if _, err := limiters.Acquire("default/llm", RateLimits{RequestsPerMinute: 2, MaxConcurrency: 2}); !errors.As(err, &throttled) ||
+		throttled.Limit != "max concurrency" {
+		t.Errorf("expected the calls in flight to be kept, got %v", err)
+	}
+
+	if _, err := limiters.Acquire("default/llm", RateLimits{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(limiters.limiters) != 0 {
+		t.Errorf("expected the limiter to be dropped once the limits are removed, got %d limiters", len(limiters.limiters))
+	}
+}
+
+func TestLimitersForget(t *testing.T) {
+	limiters := NewLimiters()
+	limits := RateLimits{RequestsPerMinute: 1}
+	release, err := limiters.Acquire("default/llm", limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiters.Forget("default/llm")
+	if len(limiters.limiters) != 0 {
+		t.Errorf("expected the limiter to be dropped, got %d limiters", len(limiters.limiters))
+	}
+	// the release of a call to a forgotten llm is ignored
+	release(Usage{TotalTokens: 10})
+	if _, err := limiters.Acquire("default/llm", limits); err != nil {
+		t.Errorf("expected a new limiter once the llm is recreated: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package llms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+var (
+	ErrUnsupportedLLMType = errors.New("unsupported llm type")
+)
+
+// Factory creates a llm client which connects to its api with opts
+type Factory func(opts ClientOptions) (LLM, error)
+
+// ValidateFunc checks whether model can be served by the llm client,
+// options such as the max tokens are applied to the validation call
+type ValidateFunc func(ctx context.Context, llm LLM, model string, options ...langchainllms.CallOption) (Response, error)
+
+// Provider describes a llm type which can be used by LLM and Prompt.
+// Providers register themselves with Register, usually in the init func of their package.
+type Provider struct {
+	// Type of the llm this provider serves
+	Type LLMType
+	// New creates the llm client
+	New Factory
+	// DefaultModels used when LLM does not list its models
+	DefaultModels []string
+	// NewParams returns the default model params for a prompt call
+	NewParams func() ModelParams
+	// Validate checks a model of this provider, DefaultValidate is used if not set
+	Validate ValidateFunc
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[LLMType]Provider)
+)
+
+// Register adds a provider to the registry. It fails if the llm type is already registered.
+func Register(provider Provider) error {
+	if provider.Type == "" {
+		return errors.New("provider type cannot be empty")
+	}
+	if provider.New == nil {
+		return fmt.Errorf("provider %s has no factory", provider.Type)
+	}
+	if provider.NewParams == nil {
+		return fmt.Errorf("provider %s has no model params", provider.Type)
+	}
+	if provider.Validate == nil {
+		provider.Validate = DefaultValidate
+	}
+
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if _, ok := providers[provider.Type]; ok {
+		return fmt.Errorf("provider %s already registered", provider.Type)
+	}
+	providers[provider.Type] = provider
+	return nil
+}
+
+// MustRegister is like Register but panics if the provider can not be registered
+func MustRegister(provider Provider) {
+	if err := Register(provider); err != nil {
+		panic(err)
+	}
+}
+
+// GetProvider returns the provider registered for the llm type
+func GetProvider(llmType LLMType) (Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	provider, ok := providers[llmType]
+	if !ok {
+		return Provider{}, fmt.Errorf("%w: %s", ErrUnsupportedLLMType, llmType)
+	}
+	return provider, nil
+}
+
+// RegisteredTypes returns all registered llm types in order
+func RegisteredTypes() []LLMType {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	types := make([]LLMType, 0, len(providers))
+	for t := range providers {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// DefaultValidate sends a validation call to model
+func DefaultValidate(ctx context.Context, llm LLM, model string, options ...langchainllms.CallOption) (Response, error) {
+	return llm.Validate(ctx, append([]langchainllms.CallOption{langchainllms.WithModel(model)}, options...)...)
+}
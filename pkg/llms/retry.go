@@ -0,0 +1,135 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPError is returned when a llm api responds with an unexpected status code
+type HTTPError struct {
+	StatusCode int
+	// RetryAfter is the wait time requested by the api through the Retry-After header
+	RetryAfter time.Duration
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+// NewHTTPError reads the error of a failed api response.
+// The error bodies of the supported apis all carry an error.message field.
+func NewHTTPError(api string, resp *http.Response) *HTTPError {
+	err := &HTTPError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+		Message:    fmt.Sprintf("%s api returned unexpected status code: %d", api, resp.StatusCode),
+	}
+	body := struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		err.Message = fmt.Sprintf("%s: %s", err.Message, body.Error.Message)
+	}
+	return err
+}
+
+// ParseRetryAfter parses a Retry-After header in seconds or http date format
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// IsRetryable reports whether a call failing with err may succeed when retried.
+// Throttling, server errors and network failures are retryable, anything else is fatal.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests ||
+			httpErr.StatusCode == http.StatusRequestTimeout ||
+			httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// RetryPolicy retries retryable errors with exponential backoff
+type RetryPolicy struct {
+	// MaxRetries after the first attempt
+	MaxRetries int
+	// InitialBackoff is the wait time before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait time between two attempts, including the one requested by Retry-After
+	MaxBackoff time.Duration
+	// Multiplier of the wait time after each retry
+	Multiplier float64
+}
+
+// DefaultRetryPolicy is used for llms which have no retry policy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+}
+
+// Backoff returns the wait time before retry n, starting from 0
+func (p RetryPolicy) Backoff(n int, err error) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	wait := time.Duration(float64(p.InitialBackoff) * math.Pow(multiplier, float64(n)))
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		wait = httpErr.RetryAfter
+	}
+	if p.MaxBackoff > 0 && (wait > p.MaxBackoff || wait < 0) {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+// Do calls fn until it succeeds, fails with a fatal error or the retries are used up.
+// It returns the number of retries made and the last error.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) (int, error) {
+	retries := 0
+	for {
+		err := fn()
+		if err == nil || !IsRetryable(err) || retries >= p.MaxRetries {
+			return retries, err
+		}
+		timer := time.NewTimer(p.Backoff(retries, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return retries, errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+		retries++
+	}
+}
@@ -0,0 +1,215 @@
+package llms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "empty", value: "", want: 0},
+		{name: "seconds", value: "3", want: 3 * time.Second},
+		{name: "zero seconds", value: "0", want: 0},
+		{name: "negative seconds", value: "-1", want: 0},
+		{name: "invalid", value: "soon", want: 0},
+		{name: "past date", value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRetryAfter(tt.value); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	// the wait time of a date is counted from now, it is a little less than the time to the date
+	got := ParseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if got <= 50*time.Second || got > time.Minute {
+		t.Errorf("expected about a minute for a date a minute ahead, got %s", got)
+	}
+}
+
+func TestNewHTTPError(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		retryAfter  string
+		body        string
+		wantMessage string
+		wantWait    time.Duration
+	}{
+		{
+			name:        "api message",
+			status:      http.StatusTooManyRequests,
+			retryAfter:  "2",
+			body:        `{"error":{"message":"slow down"}}`,
+			wantMessage: "test api returned unexpected status code: 429: slow down",
+			wantWait:    2 * time.Second,
+		},
+		{
+			name:        "no json body",
+			status:      http.StatusBadGateway,
+			body:        "bad gateway",
+			wantMessage: "test api returned unexpected status code: 502",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(tt.body))}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			err := NewHTTPError("test", resp)
+			if err.StatusCode != tt.status || err.RetryAfter != tt.wantWait || err.Message != tt.wantMessage {
+				t.Errorf("unexpected error %+v", err)
+			}
+		})
+	}
+}
+
+// timeoutError is a network error
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "no error", err: nil, want: false},
+		{name: "too many requests", err: &HTTPError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "request timeout", err: &HTTPError{StatusCode: http.StatusRequestTimeout}, want: true},
+		{name: "server error", err: &HTTPError{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "bad request", err: &HTTPError{StatusCode: http.StatusBadRequest}, want: false},
+		{name: "unauthorized", err: &HTTPError{StatusCode: http.StatusUnauthorized}, want: false},
+		{name: "wrapped http error", err: fmt.Errorf("call: %w", &HTTPError{StatusCode: http.StatusBadGateway}), want: true},
+		{name: "network error", err: timeoutError{}, want: true},
+		{name: "unexpected eof", err: fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), want: true},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "other error", err: errors.New("invalid params"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("expected retryable %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 30 * time.Second, Multiplier: 2}
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		n      int
+		err    error
+		want   time.Duration
+	}{
+		{name: "first retry", policy: policy, n: 0, want: time.Second},
+		{name: "third retry", policy: policy, n: 2, want: 4 * time.Second},
+		{name: "capped", policy: policy, n: 10, want: 30 * time.Second},
+		{name: "custom multiplier", policy: RetryPolicy{InitialBackoff: time.Second, Multiplier: 1.5}, n: 2, want: 2250 * time.Millisecond},
+		{name: "multiplier below 1", policy: RetryPolicy{InitialBackoff: time.Second, Multiplier: 0.5}, n: 3, want: time.Second},
+		{name: "uncapped", policy: RetryPolicy{InitialBackoff: time.Second, Multiplier: 2}, n: 6, want: 64 * time.Second},
+		{
+			name:   "retry after",
+			policy: policy,
+			n:      0,
+			err:    &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 10 * time.Second},
+			want:   10 * time.Second,
+		},
+		{
+			name:   "retry after is capped",
+			policy: policy,
+			n:      0,
+			err:    &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour},
+			want:   30 * time.Second,
+		},
+		{name: "overflow is capped", policy: policy, n: 1000, want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Backoff(tt.n, tt.err); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond, Multiplier: 2}
+	unavailable := &HTTPError{StatusCode: http.StatusServiceUnavailable, Message: "unavailable"}
+	tests := []struct {
+		name        string
+		policy      RetryPolicy
+		errs        []error
+		wantCalls   int
+		wantRetries int
+		wantErr     bool
+	}{
+		{name: "success", policy: policy, errs: []error{nil}, wantCalls: 1},
+		{name: "success after retries", policy: policy, errs: []error{unavailable, unavailable, nil}, wantCalls: 3, wantRetries: 2},
+		{
+			name:        "retries used up",
+			policy:      policy,
+			errs:        []error{unavailable, unavailable, unavailable, nil},
+			wantCalls:   3,
+			wantRetries: 2,
+			wantErr:     true,
+		},
+		{
+			name:      "fatal error",
+			policy:    policy,
+			errs:      []error{&HTTPError{StatusCode: http.StatusBadRequest, Message: "bad request"}, nil},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{name: "retries disabled", policy: RetryPolicy{}, errs: []error{unavailable, nil}, wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			retries, err := tt.policy.Do(context.Background(), func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if calls != tt.wantCalls || retries != tt.wantRetries || (err != nil) != tt.wantErr {
+				t.Errorf("expected %d calls, %d retries and error %v, got %d calls, %d retries and error %v",
+					tt.wantCalls, tt.wantRetries, tt.wantErr, calls, retries, err)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoCanceled(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, InitialBackoff: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	retries, err := policy.Do(ctx, func() error {
+		calls++
+		cancel()
+		return &HTTPError{StatusCode: http.StatusServiceUnavailable, Message: "unavailable"}
+	})
+	if calls != 1 || retries != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the backoff to end with the context, got %d calls, %d retries and error %v", calls, retries, err)
+	}
+}
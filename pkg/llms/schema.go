@@ -0,0 +1,27 @@
+package llms
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// ParseResponse decodes the json output of a llm, validates it against schema
+// and returns it re-encoded as compact json
+func ParseResponse(schema []byte, output string) (json.RawMessage, error) {
+	s := &spec.Schema{}
+	if err := json.Unmarshal(schema, s); err != nil {
+		return nil, fmt.Errorf("invalid response schema: %w", err)
+	}
+	var result interface{}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("response is not valid json: %w", err)
+	}
+	if err := validate.NewSchemaValidator(s, nil, "", strfmt.Default).Validate(result).AsError(); err != nil {
+		return nil, fmt.Errorf("response does not match response schema: %w", err)
+	}
+	return json.Marshal(result)
+}
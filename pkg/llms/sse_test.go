@@ -0,0 +1,42 @@
+package llms
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadEvents(t *testing.T) {
+	type event struct {
+		name string
+		data string
+	}
+	tests := []struct {
+		name   string
+		stream string
+		want   []event
+	}{
+		{
+			name:   "named events",
+			stream: "event: message_start\ndata: {\"a\":1}\n\nevent: message_stop\ndata: {}\n\n",
+			want:   []event{{name: "message_start", data: `{"a":1}`}, {name: "message_stop", data: "{}"}},
+		},
+		{name: "multi line data", stream: "data: line 1\ndata: line 2\n\n", want: []event{{data: "line 1\nline 2"}}},
+		{name: "comments are skipped", stream: ": keep-alive\n\ndata: [DONE]", want: []event{{data: "[DONE]"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []event
+			err := ReadEvents(strings.NewReader(tt.stream), func(name string, data []byte) error {
+				got = append(got, event{name: name, data: string(data)})
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected events %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
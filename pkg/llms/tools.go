@@ -0,0 +1,50 @@
+package llms
+
+import (
+	"encoding/json"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+// Tool is a function which the llm can ask to call
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters is the json schema of the function arguments
+	Parameters json.RawMessage
+}
+
+// ToolCall is a call to a tool requested by the llm
+type ToolCall struct {
+	// ID identifies the call, tool messages answer it with the same id
+	ID string `json:"id,omitempty"`
+	// Name of the tool to call
+	Name string `json:"name"`
+	// Arguments encoded as a json object
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// WithTools makes the tools available to the llm in a call
+func WithTools(tools []Tool) langchainllms.CallOption {
+	functions := make([]langchainllms.Tool, 0, len(tools))
+	for _, tool := range tools {
+		definition := &langchainllms.FunctionDefinition{
+			Name:        tool.Name,
+			Description: tool.Description,
+		}
+		if len(tool.Parameters) > 0 {
+			definition.Parameters = tool.Parameters
+		}
+		functions = append(functions, langchainllms.Tool{Type: "function", Function: definition})
+	}
+	return langchainllms.WithTools(functions)
+}
+
+// ToolParameters returns the json schema of the tool arguments,
+// falling back to an object without properties if it has none
+func ToolParameters(tool langchainllms.Tool) (json.RawMessage, error) {
+	if tool.Function == nil || tool.Function.Parameters == nil {
+		return json.RawMessage(`{"type":"object"}`), nil
+	}
+	return json.Marshal(tool.Function.Parameters)
+}
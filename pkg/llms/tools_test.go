@@ -0,0 +1,32 @@
+package llms
+
+import (
+	"encoding/json"
+	"testing"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+func TestToolParameters(t *testing.T) {
+	schema := `{"type":"object","properties":{"city":{"type":"string"}}}`
+	tests := []struct {
+		name string
+		tool langchainllms.Tool
+		want string
+	}{
+		{name: "parameters", tool: langchainllms.Tool{Function: &langchainllms.FunctionDefinition{Name: "get_weather", Parameters: json.RawMessage(schema)}}, want: schema},
+		{name: "no parameters", tool: langchainllms.Tool{Function: &langchainllms.FunctionDefinition{Name: "now"}}, want: `{"type":"object"}`},
+		{name: "no function", tool: langchainllms.Tool{Type: "function"}, want: `{"type":"object"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToolParameters(tt.tool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected parameters %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
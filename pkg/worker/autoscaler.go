@@ -0,0 +1,240 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+
+	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
+)
+
+// AnnotationWakeUp is set on a worker scaled to zero by the first prompt calling it, with the time of the call in RFC3339
+const AnnotationWakeUp = basev1alpha1.Group + "/wake-up"
+
+// scrapeTimeout bounds the scrape of the metrics of one runner
+const scrapeTimeout = 5 * time.Second
+
+// RunnerMetrics names the prometheus metrics a runner reports its load with
+type RunnerMetrics struct {
+	// Path the metrics are served on, on the port of the api
+	Path string
+	// InFlight are the gauges of the running and the waiting requests, they are summed up
+	InFlight []string
+	// GeneratedTokens is the counter of the generated tokens
+	GeneratedTokens string
+}
+
+// Load of the runners of a worker
+type Load struct {
+	// InFlight requests of all runners
+	InFlight float64
+	// TokensPerSecond generated by all runners since the last scrape
+	TokensPerSecond float64
+}
+
+// Autoscaler decides the replicas of workers from the load scraped from their runners.
+// It keeps the token counters of the runners between two scrapes to compute the token rate,
+// and the last time a worker was busy to scale it to zero once it is idle.
+type Autoscaler struct {
+	httpClient *http.Client
+
+	// mu guards workers, each state has its own lock held for a whole scaling decision
+	mu      sync.Mutex
+	workers map[string]*scaleState
+}
+
+type scaleState struct {
+	mu sync.Mutex
+	// tokens are the token counters of the last scrape, keyed by pod
+	tokens map[string]counterSample
+	// lastActive is the last time the worker had requests in flight or was woken up
+	lastActive time.Time
+}
+
+type counterSample struct {
+	value float64
+	at    time.Time
+}
+
+func NewAutoscaler() *Autoscaler {
+	return &Autoscaler{
+		httpClient: &http.Client{Timeout: scrapeTimeout},
+		workers:    make(map[string]*scaleState),
+	}
+}
+
+// Forget drops the state of the worker identified by key once it is deleted
+func (a *Autoscaler) Forget(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.workers, key)
+}
+
+// Scale returns the replicas of worker for the load of its pods, current is the replicas it has now.
+// Replicas are added at once, but only removed after the scale down delay since the last scaling.
+func (a *Autoscaler) Scale(ctx context.Context, key string, worker *basev1alpha1.Worker, pods []corev1.Pod, current int32) (int32, Load, error) {
+	spec := worker.Spec.Autoscaling
+	metrics := RunnerFor(worker.Spec.Type).Metrics()
+	if metrics == nil {
+		return current, Load{}, fmt.Errorf("runner of worker type %s exposes no metrics to autoscale with", worker.Spec.Type)
+	}
+	minReplicas := spec.GetMinReplicas()
+	now := time.Now()
+
+	a.mu.Lock()
+	state, ok := a.workers[key]
+	if !ok {
+		// the worker counts as busy when it is first seen, so that it is not scaled to zero right after a restart
+		state = &scaleState{tokens: make(map[string]counterSample), lastActive: now}
+		a.workers[key] = state
+	}
+	a.mu.Unlock()
+	// the scrapes of other workers go on while this worker is scaled
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	woken := false
+	if wakeUp, err := time.Parse(time.RFC3339, worker.Annotations[AnnotationWakeUp]); err == nil {
+		woken = worker.Status.LastScaleTime == nil || wakeUp.After(worker.Status.LastScaleTime.Time)
+		if wakeUp.After(state.lastActive) {
+			state.lastActive = wakeUp
+		}
+	}
+
+	if current == 0 {
+		if minReplicas > 0 || woken {
+			return max(minReplicas, 1), Load{}, nil
+		}
+		return 0, Load{}, nil
+	}
+
+	if !lo.SomeBy(pods, func(pod corev1.Pod) bool { return podReady(&pod) }) {
+		// the load is unknown until a runner is ready, for example while the model is loaded
+		return current, Load{}, nil
+	}
+	load, err := a.scrape(ctx, state, pods, metrics, now)
+	if err != nil {
+		// keep the replicas until the load is known again
+		return current, load, err
+	}
+	if load.InFlight > 0 || load.TokensPerSecond > 0 {
+		state.lastActive = now
+	}
+
+	desired := DesiredReplicas(spec, load)
+	if minReplicas == 0 && now.Sub(state.lastActive) >= spec.GetScaleToZeroAfter() {
+		desired = 0
+	}
+	if desired < current && worker.Status.LastScaleTime != nil && now.Sub(worker.Status.LastScaleTime.Time) < spec.GetScaleDownDelay() {
+		desired = current
+	}
+	return min(desired, spec.MaxReplicas), load, nil
+}
+
+// DesiredReplicas returns the replicas needed to serve load with the targets of spec, at least one and at least MinReplicas
+func DesiredReplicas(spec *basev1alpha1.Autoscaling, load Load) int32 {
+	desired := max(spec.GetMinReplicas(), 1)
+	if spec.TargetInFlightRequests > 0 {
+		desired = max(desired, int32(math.Ceil(load.InFlight/float64(spec.TargetInFlightRequests))))
+	}
+	if spec.TargetTokensPerSecond > 0 {
+		desired = max(desired, int32(math.Ceil(load.TokensPerSecond/float64(spec.TargetTokensPerSecond))))
+	}
+	return min(desired, spec.MaxReplicas)
+}
+
+// scrape sums up the load of the ready pods, it fails only if no ready pod could be scraped
+func (a *Autoscaler) scrape(ctx context.Context, state *scaleState, pods []corev1.Pod, metrics *RunnerMetrics, now time.Time) (Load, error) {
+	var (
+		load    Load
+		errs    []error
+		scraped int
+	)
+	tokens := make(map[string]counterSample)
+	for i := range pods {
+		pod := &pods[i]
+		if !podReady(pod) || pod.Status.PodIP == "" {
+			continue
+		}
+		families, err := a.scrapePod(ctx, pod, metrics)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pod %s: %w", pod.Name, err))
+			continue
+		}
+		scraped++
+		for _, name := range metrics.InFlight {
+			load.InFlight += sum(families[name])
+		}
+		if metrics.GeneratedTokens == "" {
+			continue
+		}
+		sample := counterSample{value: sum(families[metrics.GeneratedTokens]), at: now}
+		// a counter reset by a restart of the runner is skipped once
+		if last, ok := state.tokens[pod.Name]; ok && sample.value >= last.value && sample.at.After(last.at) {
+			load.TokensPerSecond += (sample.value - last.value) / sample.at.Sub(last.at).Seconds()
+		}
+		tokens[pod.Name] = sample
+	}
+	state.tokens = tokens
+	if scraped == 0 && len(errs) > 0 {
+		return load, fmt.Errorf("scrape runner metrics: %w", errors.Join(errs...))
+	}
+	return load, nil
+}
+
+// scrapePod returns the metric families served by the runner of pod
+func (a *Autoscaler) scrapePod(ctx context.Context, pod *corev1.Pod, metrics *RunnerMetrics) (map[string]*dto.MetricFamily, error) {
+	url := fmt.Sprintf("http://%s:%d%s", pod.Status.PodIP, Port, metrics.Path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: %s", url, resp.Status)
+	}
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(resp.Body)
+}
+
+// sum adds up the values of all series of family, it is 0 if the family is missing
+func sum(family *dto.MetricFamily) float64 {
+	if family == nil {
+		return 0
+	}
+	var total float64
+	for _, m := range family.GetMetric() {
+		switch {
+		case m.Gauge != nil:
+			total += m.GetGauge().GetValue()
+		case m.Counter != nil:
+			total += m.GetCounter().GetValue()
+		case m.Untyped != nil:
+			total += m.GetUntyped().GetValue()
+		}
+	}
+	return total
+}
+
+// podReady reports whether pod passes its readiness probe
+func podReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
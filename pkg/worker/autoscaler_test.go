@@ -0,0 +1,183 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
+)
+
+// roundTripperFunc serves the scrapes of the autoscaler without a runner
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// runnerMetrics returns a client serving the vllm metrics of a runner with running requests and generated tokens
+func runnerMetrics(running, tokens float64) *http.Client {
+	return &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/metrics" {
+			return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: http.NoBody}, nil
+		}
+		body := fmt.Sprintf(`# TYPE vllm:num_requests_running gauge
+vllm:num_requests_running{model_name="model"} %v
+# TYPE vllm:num_requests_waiting gauge
+vllm:num_requests_waiting{model_name="model"} 0
+# TYPE vllm:generation_tokens_total counter
+vllm:generation_tokens_total{model_name="model"} %v
+`, running, tokens)
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader(body))}, nil
+	})}
+}
+
+func testPod(name string, ready bool) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.PodStatus{
+			PodIP: "10.0.0.1",
+			Conditions: []corev1.PodCondition{{
+				Type:   corev1.PodReady,
+				Status: lo.Ternary(ready, corev1.ConditionTrue, corev1.ConditionFalse),
+			}},
+		},
+	}
+}
+
+func TestDesiredReplicas(t *testing.T) {
+	tests := []struct {
+		name string
+		spec basev1alpha1.Autoscaling
+		load Load
+		want int32
+	}{
+		{
+			name: "idle with min replicas 0",
+			spec: basev1alpha1.Autoscaling{MinReplicas: lo.ToPtr[int32](0), MaxReplicas: 4, TargetInFlightRequests: 8},
+			want: 1,
+		},
+		{name: "in flight", spec: basev1alpha1.Autoscaling{MaxReplicas: 4, TargetInFlightRequests: 8}, load: Load{InFlight: 17}, want: 3},
+		{
+			name: "higher of both targets",
+			spec: basev1alpha1.Autoscaling{MaxReplicas: 8, TargetInFlightRequests: 8, TargetTokensPerSecond: 100},
+			load: Load{InFlight: 8, TokensPerSecond: 450},
+			want: 5,
+		},
+		{name: "capped at max replicas", spec: basev1alpha1.Autoscaling{MaxReplicas: 4, TargetInFlightRequests: 8}, load: Load{InFlight: 100}, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DesiredReplicas(&tt.spec, tt.load); got != tt.want {
+				t.Errorf("expected %d replicas, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAutoscalerScale(t *testing.T) {
+	scaleToZero := basev1alpha1.Autoscaling{MinReplicas: lo.ToPtr[int32](0), MaxReplicas: 4, TargetInFlightRequests: 8}
+	tests := []struct {
+		name        string
+		autoscaling basev1alpha1.Autoscaling
+		current     int32
+		running     float64
+		// the times are relative to now, zero leaves them unset
+		lastScale  time.Duration
+		lastActive time.Duration
+		wakeUp     time.Duration
+		want       int32
+	}{
+		{name: "scale up", autoscaling: scaleToZero, current: 1, running: 20, want: 3},
+		{name: "scale down within the delay", autoscaling: scaleToZero, current: 3, lastScale: -time.Minute, want: 3},
+		{name: "scale down after the delay", autoscaling: scaleToZero, current: 3, lastScale: -10 * time.Minute, want: 1},
+		{
+			name:        "scale to zero once idle",
+			autoscaling: scaleToZero,
+			current:     1,
+			lastScale:   -time.Hour,
+			lastActive:  -20 * time.Minute,
+			want:        0,
+		},
+		{
+			name:        "recent wake-up keeps the worker",
+			autoscaling: scaleToZero,
+			current:     1,
+			lastScale:   -time.Hour,
+			lastActive:  -20 * time.Minute,
+			wakeUp:      -time.Minute,
+			want:        1,
+		},
+		{name: "stays at zero", autoscaling: scaleToZero, lastScale: -time.Hour, lastActive: -time.Hour, want: 0},
+		{
+			name:        "woken up",
+			autoscaling: scaleToZero,
+			lastScale:   -time.Hour,
+			lastActive:  -time.Hour,
+			wakeUp:      -time.Minute,
+			want:        1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			worker := &basev1alpha1.Worker{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"},
+				Spec:       basev1alpha1.WorkerSpec{Type: basev1alpha1.WorkerTypeFastchatVLLM, Autoscaling: &tt.autoscaling},
+			}
+			if tt.lastScale != 0 {
+				worker.Status.LastScaleTime = &metav1.Time{Time: now.Add(tt.lastScale)}
+			}
+			if tt.wakeUp != 0 {
+				worker.Annotations = map[string]string{AnnotationWakeUp: now.Add(tt.wakeUp).Format(time.RFC3339)}
+			}
+			a := NewAutoscaler()
+			a.httpClient = runnerMetrics(tt.running, 0)
+			if tt.lastActive != 0 {
+				a.workers["default/worker"] = &scaleState{tokens: map[string]counterSample{}, lastActive: now.Add(tt.lastActive)}
+			}
+			pods := []corev1.Pod{testPod("worker-0", true)}
+			got, load, err := a.Scale(context.Background(), "default/worker", worker, pods, tt.current)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %d replicas, got %d with load %+v", tt.want, got, load)
+			}
+		})
+	}
+}
+
+func TestAutoscalerConcurrentScale(t *testing.T) {
+	worker := &basev1alpha1.Worker{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"},
+		Spec: basev1alpha1.WorkerSpec{Type: basev1alpha1.WorkerTypeFastchatVLLM,
+			Autoscaling: &basev1alpha1.Autoscaling{MaxReplicas: 4, TargetInFlightRequests: 8}},
+	}
+	a := NewAutoscaler()
+	a.httpClient = runnerMetrics(20, 1000)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := a.Scale(context.Background(), "default/worker", worker, []corev1.Pod{testPod("worker-0", true)}, 1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	a.Forget("default/worker")
+	if len(a.workers) != 0 {
+		t.Errorf("expected the state of the worker to be forgotten, got %d states", len(a.workers))
+	}
+}
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"fmt"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+
+	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
+	"github.com/fleezesd/llm-operator/pkg/model"
+)
+
+// LoaderContainer downloads the model files into the storage before the runner starts
+const LoaderContainer = "loader"
+
+// env the loader image reads the model source from.
+// It downloads the files into MODEL_PATH and is expected to skip the files already there.
+const (
+	EnvLoaderSource    = "LOADER_SOURCE"
+	EnvLoaderRepo      = "LOADER_REPO"
+	EnvLoaderRevision  = "LOADER_REVISION"
+	EnvLoaderEndpoint  = "LOADER_ENDPOINT"
+	EnvLoaderInsecure  = "LOADER_INSECURE"
+	EnvLoaderBucket    = "LOADER_BUCKET"
+	EnvLoaderObject    = "LOADER_OBJECT"
+	EnvLoaderVersionID = "LOADER_VERSION_ID"
+	EnvLoaderPath      = "LOADER_PATH"
+	// EnvLoaderAuthPrefix prefixes the keys of the datasource auth secret
+	EnvLoaderAuthPrefix = "LOADER_AUTH_"
+)
+
+// values of EnvLoaderSource
+const (
+	LoaderSourceHuggingFace = "huggingface"
+	LoaderSourceModelScope  = "modelscope"
+	LoaderSourceOSS         = "oss"
+	LoaderSourceRDMA        = "rdma"
+)
+
+// Loader renders the init container loading the files of m, it is nil if m has no source.
+// ds is the datasource m.Spec.Source refers to.
+func Loader(worker *basev1alpha1.Worker, m *basev1alpha1.Model, ds *basev1alpha1.DataSource) (*corev1.Container, error) {
+	env, err := loaderEnv(m, ds)
+	if err != nil || env == nil {
+		return nil, err
+	}
+	if worker.Spec.Loader.Image == "" {
+		return nil, fmt.Errorf("loader image is required to load model %s from %s", m.Name, DescribeSource(m))
+	}
+	container := &corev1.Container{
+		Name:            LoaderContainer,
+		Image:           worker.Spec.Loader.Image,
+		ImagePullPolicy: worker.Spec.Loader.ImagePullPolicy,
+		// additional envs come first so that they take precedence, they carry tokens or proxies as well
+		Env: model.UniqEnvVar(append(append(append([]corev1.EnvVar{}, worker.Spec.AdditionalEnvs...),
+			corev1.EnvVar{Name: EnvModelName, Value: ServedModelName(m)},
+			corev1.EnvVar{Name: EnvModelPath, Value: ModelPath(m)},
+		), env...)),
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      ModelsVolume,
+			MountPath: ModelsPath,
+		}},
+		// the last lines of the output explain a failure in the Loaded condition
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+	if ds != nil && ds.Spec.Endpoint.AuthSecret != nil {
+		container.EnvFrom = []corev1.EnvFromSource{{
+			Prefix:    EnvLoaderAuthPrefix,
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: ds.Spec.Endpoint.AuthSecret.Name}},
+		}}
+	}
+	return container, nil
+}
+
+// loaderEnv returns the env describing the source of m, it is nil if m has no source
+func loaderEnv(m *basev1alpha1.Model, ds *basev1alpha1.DataSource) ([]corev1.EnvVar, error) {
+	var env []corev1.EnvVar
+	add := func(name, value string) {
+		if value != "" {
+			env = append(env, corev1.EnvVar{Name: name, Value: value})
+		}
+	}
+	switch {
+	case m.Spec.Source != nil:
+		if ds == nil {
+			return nil, fmt.Errorf("datasource %s of model %s not found", m.Spec.Source.Name, m.Name)
+		}
+		switch {
+		case ds.Spec.OSS != nil:
+			add(EnvLoaderSource, LoaderSourceOSS)
+			add(EnvLoaderBucket, ds.Spec.OSS.Bucket)
+			add(EnvLoaderObject, ds.Spec.OSS.Object)
+			add(EnvLoaderVersionID, ds.Spec.OSS.VersionID)
+		case ds.Spec.RDMA != nil:
+			add(EnvLoaderSource, LoaderSourceRDMA)
+			add(EnvLoaderPath, ds.Spec.RDMA.Path)
+		default:
+			return nil, fmt.Errorf("datasource %s can not provide model files, only oss and rdma datasources can", ds.Name)
+		}
+		add(EnvLoaderEndpoint, ds.Spec.Endpoint.GetURL())
+		add(EnvLoaderInsecure, lo.Ternary(ds.Spec.Endpoint.Insecure, "true", ""))
+	case m.Spec.HuggingFaceRepo != "":
+		add(EnvLoaderSource, LoaderSourceHuggingFace)
+		add(EnvLoaderRepo, m.Spec.HuggingFaceRepo)
+		add(EnvLoaderRevision, m.Spec.Revision)
+	case m.Spec.ModelScopeRepo != "":
+		add(EnvLoaderSource, LoaderSourceModelScope)
+		add(EnvLoaderRepo, m.Spec.ModelScopeRepo)
+		add(EnvLoaderRevision, m.Spec.Revision)
+	}
+	return env, nil
+}
+
+// DescribeSource returns a readable description of where the files of m are loaded from
+func DescribeSource(m *basev1alpha1.Model) string {
+	switch {
+	case m.Spec.Source != nil:
+		return fmt.Sprintf("datasource %s", m.Spec.Source.Name)
+	case m.Spec.HuggingFaceRepo != "":
+		return fmt.Sprintf("huggingface repo %s", m.Spec.HuggingFaceRepo)
+	case m.Spec.ModelScopeRepo != "":
+		return fmt.Sprintf("modelscope repo %s", m.Spec.ModelScopeRepo)
+	}
+	return "the worker storage"
+}
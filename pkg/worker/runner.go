@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"errors"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
+	"github.com/fleezesd/llm-operator/pkg/model"
+)
+
+// env the runner image reads the model to serve from
+const (
+	EnvModelName = "MODEL_NAME"
+	EnvModelPath = "MODEL_PATH"
+	EnvPort      = "PORT"
+)
+
+// Runner renders the container serving the model of a worker
+type Runner interface {
+	// Container returns the runner container of worker serving model
+	Container(worker *basev1alpha1.Worker, model *basev1alpha1.Model) (corev1.Container, error)
+	// APIPath returns the path of the openai compatible api of the runner, it is empty if the runner serves none
+	APIPath() string
+	// Metrics returns the prometheus metrics the runner reports its load with, it is nil if it reports none
+	Metrics() *RunnerMetrics
+}
+
+// runners have a profile for the command, args and probes of a worker type
+var runners = map[basev1alpha1.WorkerType]Runner{
+	// the fastchat image runs the openai api server of fastchat in front of its model worker
+	basev1alpha1.WorkerTypeFastchatNormal: imageRunner{apiPath: "/v1"},
+	basev1alpha1.WorkerTypeFastchatVLLM:   vllmRunner{},
+}
+
+// RunnerFor returns the runner of the worker type.
+// Types without a profile run the entrypoint of the runner image, which reads the model from env.
+func RunnerFor(workerType basev1alpha1.WorkerType) Runner {
+	if runner, ok := runners[workerType]; ok {
+		return runner
+	}
+	return imageRunner{}
+}
+
+// imageRunner runs the entrypoint of the runner image, apiPath is the path of its openai compatible api if it serves one
+type imageRunner struct {
+	apiPath string
+}
+
+func (imageRunner) Container(worker *basev1alpha1.Worker, m *basev1alpha1.Model) (corev1.Container, error) {
+	if worker.Spec.Runner.Image == "" {
+		return corev1.Container{}, errors.New("runner image is required")
+	}
+	container := baseContainer(worker, m)
+	container.ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(PortName)},
+		},
+		PeriodSeconds: 10,
+	}
+	return container, nil
+}
+
+func (r imageRunner) APIPath() string {
+	return r.apiPath
+}
+
+func (imageRunner) Metrics() *RunnerMetrics {
+	return nil
+}
+
+// baseContainer returns the runner container without command and probes
+func baseContainer(worker *basev1alpha1.Worker, m *basev1alpha1.Model) corev1.Container {
+	return corev1.Container{
+		Name:            RunnerContainer,
+		Image:           worker.Spec.Runner.Image,
+		ImagePullPolicy: worker.Spec.Runner.ImagePullPolicy,
+		Ports: []corev1.ContainerPort{{
+			Name:          PortName,
+			Protocol:      corev1.ProtocolTCP,
+			ContainerPort: Port,
+		}},
+		// additional envs come first so that they take precedence
+		Env: model.UniqEnvVar(append(append([]corev1.EnvVar{}, worker.Spec.AdditionalEnvs...),
+			corev1.EnvVar{Name: EnvModelName, Value: ServedModelName(m)},
+			corev1.EnvVar{Name: EnvModelPath, Value: ModelPath(m)},
+			corev1.EnvVar{Name: EnvPort, Value: strconv.Itoa(Port)},
+		)),
+		Resources: worker.Spec.Resources,
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      ModelsVolume,
+			MountPath: ModelsPath,
+		}},
+	}
+}
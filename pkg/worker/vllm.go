@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
+)
+
+const (
+	// VLLMImage runs the openai compatible api server of vllm, it is used if the worker has no runner image
+	VLLMImage = "vllm/vllm-openai:v0.6.3"
+	// ResourceGPU is the resource the gpus of a worker are requested with
+	ResourceGPU corev1.ResourceName = "nvidia.com/gpu"
+)
+
+// vllmRunner serves the model with the openai compatible api server of vllm
+type vllmRunner struct{}
+
+func (vllmRunner) Container(worker *basev1alpha1.Worker, m *basev1alpha1.Model) (corev1.Container, error) {
+	container := baseContainer(worker, m)
+	if container.Image == "" {
+		container.Image = VLLMImage
+	}
+	// the entrypoint of the image starts the api server, only the args are set
+	container.Args = vllmArgs(worker, m)
+
+	health := corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromString(PortName)},
+	}
+	// loading the weights of large models takes a while, the other probes start once it succeeded
+	container.StartupProbe = &corev1.Probe{
+		ProbeHandler:     health,
+		PeriodSeconds:    10,
+		FailureThreshold: 180,
+	}
+	container.ReadinessProbe = &corev1.Probe{
+		ProbeHandler:  health,
+		PeriodSeconds: 10,
+	}
+	container.LivenessProbe = &corev1.Probe{
+		ProbeHandler:     health,
+		PeriodSeconds:    30,
+		FailureThreshold: 3,
+	}
+	return container, nil
+}
+
+func (vllmRunner) APIPath() string {
+	return "/v1"
+}
+
+func (vllmRunner) Metrics() *RunnerMetrics {
+	return &RunnerMetrics{
+		Path:            "/metrics",
+		InFlight:        []string{"vllm:num_requests_running", "vllm:num_requests_waiting"},
+		GeneratedTokens: "vllm:generation_tokens_total",
+	}
+}
+
+// vllmArgs returns the args of the vllm api server serving m
+func vllmArgs(worker *basev1alpha1.Worker, m *basev1alpha1.Model) []string {
+	args := []string{
+		"--model", ModelPath(m),
+		"--served-model-name", ServedModelName(m),
+		"--host", "0.0.0.0",
+		"--port", strconv.Itoa(Port),
+	}
+	if gpus := GPUs(worker); gpus > 1 {
+		args = append(args, "--tensor-parallel-size", strconv.FormatInt(gpus, 10))
+	}
+	if m.Spec.MaxContextLength > 0 {
+		args = append(args, "--max-model-len", strconv.Itoa(m.Spec.MaxContextLength))
+	}
+	return args
+}
+
+// GPUs returns the number of gpus requested by worker, extended resources may be set as limits only
+func GPUs(worker *basev1alpha1.Worker) int64 {
+	if gpus, ok := worker.Spec.Resources.Requests[ResourceGPU]; ok {
+		return gpus.Value()
+	}
+	if gpus, ok := worker.Spec.Resources.Limits[ResourceGPU]; ok {
+		return gpus.Value()
+	}
+	return 0
+}
@@ -0,0 +1,209 @@
+package worker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
+	"github.com/fleezesd/llm-operator/pkg/llms"
+)
+
+const (
+	// LabelWorker is set on the objects rendered for a worker
+	LabelWorker = basev1alpha1.Group + "/worker"
+	// AnnotationTemplateHash records the hash of the rendered pod template,
+	// the deployment is only updated when it changes
+	AnnotationTemplateHash = basev1alpha1.Group + "/template-hash"
+
+	// ModelsVolume holds the model files
+	ModelsVolume = "models"
+	// ModelsPath is where ModelsVolume is mounted in the containers
+	ModelsPath = "/data/models"
+
+	// RunnerContainer serves the model
+	RunnerContainer = "runner"
+	// PortName is the name of the port of the api served by the runner
+	PortName = "http"
+	// Port of the api served by the runner
+	Port = 21002
+)
+
+// Labels returns the labels of the objects rendered for worker
+func Labels(worker *basev1alpha1.Worker) map[string]string {
+	return map[string]string{LabelWorker: worker.Name}
+}
+
+// ModelPath returns the directory of the files of model in the runner
+func ModelPath(model *basev1alpha1.Model) string {
+	return path.Join(ModelsPath, model.Name)
+}
+
+// ServedModelName returns the model name the runner serves model with
+func ServedModelName(model *basev1alpha1.Model) string {
+	return model.Name
+}
+
+// PersistentVolumeClaim renders the claim storing the model files, it is nil if worker has no storage
+func PersistentVolumeClaim(worker *basev1alpha1.Worker) *corev1.PersistentVolumeClaim {
+	if worker.Spec.Storage == nil {
+		return nil
+	}
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      worker.Name,
+			Namespace: worker.Namespace,
+			Labels:    Labels(worker),
+		},
+		Spec: *worker.Spec.Storage.DeepCopy(),
+	}
+}
+
+// Service renders the service exposing the api of the runner
+func Service(worker *basev1alpha1.Worker) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      worker.Name,
+			Namespace: worker.Namespace,
+			Labels:    Labels(worker),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: Labels(worker),
+			Ports: []corev1.ServicePort{{
+				Name:       PortName,
+				Protocol:   corev1.ProtocolTCP,
+				Port:       Port,
+				TargetPort: intstr.FromString(PortName),
+			}},
+		},
+	}
+}
+
+// ServiceURL returns the in cluster url of the service of worker
+func ServiceURL(worker *basev1alpha1.Worker) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d", worker.Name, worker.Namespace, Port)
+}
+
+// Endpoint returns the openai compatible endpoint exposed by the service of worker,
+// it is nil if the runner of the worker type serves none
+func Endpoint(worker *basev1alpha1.Worker) *basev1alpha1.Endpoint {
+	apiPath := RunnerFor(worker.Spec.Type).APIPath()
+	if apiPath == "" {
+		return nil
+	}
+	url := ServiceURL(worker) + apiPath
+	return &basev1alpha1.Endpoint{URL: url, InternalURL: url}
+}
+
+// LLM renders the llm calling the model served by worker, it has the name of the worker.
+// It is nil if the runner of the worker type serves no openai compatible api to call.
+func LLM(worker *basev1alpha1.Worker, model *basev1alpha1.Model) *basev1alpha1.LLM {
+	if Endpoint(worker) == nil {
+		return nil
+	}
+	return &basev1alpha1.LLM{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      worker.Name,
+			Namespace: worker.Namespace,
+			Labels:    Labels(worker),
+		},
+		Spec: basev1alpha1.LLMSpec{
+			// runners serve openai compatible apis
+			Type: llms.OpenAI,
+			Provider: basev1alpha1.Provider{
+				Worker: &corev1.TypedObjectReference{
+					APIGroup: lo.ToPtr(basev1alpha1.Group),
+					Kind:     basev1alpha1.KindWorker,
+					Name:     worker.Name,
+				},
+			},
+			Models: []string{ServedModelName(model)},
+		},
+	}
+}
+
+// Deployment renders the deployment running worker to serve model.
+// ds is the datasource holding the model files if the model has one.
+func Deployment(worker *basev1alpha1.Worker, model *basev1alpha1.Model, ds *basev1alpha1.DataSource) (*appsv1.Deployment, error) {
+	runner, err := RunnerFor(worker.Spec.Type).Container(worker, model)
+	if err != nil {
+		return nil, err
+	}
+	loader, err := Loader(worker, model, ds)
+	if err != nil {
+		return nil, err
+	}
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: Labels(worker),
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: lo.Ternary(loader != nil, []corev1.Container{lo.FromPtr(loader)}, nil),
+			Containers:     []corev1.Container{runner},
+			Volumes:        []corev1.Volume{modelsVolume(worker)},
+			Affinity:       affinity(worker),
+		},
+	}
+	hash, err := templateHash(template)
+	if err != nil {
+		return nil, err
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        worker.Name,
+			Namespace:   worker.Namespace,
+			Labels:      Labels(worker),
+			Annotations: map[string]string{AnnotationTemplateHash: hash},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: lo.ToPtr(lo.FromPtrOr(worker.Spec.Replicas, 1)),
+			Selector: &metav1.LabelSelector{MatchLabels: Labels(worker)},
+			// the model storage can usually be mounted by one node only
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: template,
+		},
+	}, nil
+}
+
+// modelsVolume returns the volume of the model files, it is ephemeral if worker has no storage
+func modelsVolume(worker *basev1alpha1.Worker) corev1.Volume {
+	volume := corev1.Volume{Name: ModelsVolume}
+	if worker.Spec.Storage == nil {
+		volume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+		return volume
+	}
+	volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: worker.Name}
+	return volume
+}
+
+// affinity schedules the pods of worker to the nodes matching its expressions
+func affinity(worker *basev1alpha1.Worker) *corev1.Affinity {
+	if len(worker.Spec.MatchExpressions) == 0 {
+		return nil
+	}
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: worker.Spec.MatchExpressions}},
+			},
+		},
+	}
+}
+
+func templateHash(template corev1.PodTemplateSpec) (string, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return "", fmt.Errorf("hash pod template: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
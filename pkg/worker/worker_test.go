@@ -0,0 +1,225 @@
+package worker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	basev1alpha1 "github.com/fleezesd/llm-operator/api/base/v1alpha1"
+)
+
+func testWorker(mutate func(*basev1alpha1.Worker)) *basev1alpha1.Worker {
+	worker := &basev1alpha1.Worker{
+		ObjectMeta: metav1.ObjectMeta{Name: "qwen", Namespace: "default"},
+		Spec:       basev1alpha1.WorkerSpec{Runner: basev1alpha1.Image{Image: "runner:v1"}},
+	}
+	if mutate != nil {
+		mutate(worker)
+	}
+	return worker
+}
+
+var testModel = &basev1alpha1.Model{ObjectMeta: metav1.ObjectMeta{Name: "qwen2", Namespace: "default"}}
+
+func TestDeployment(t *testing.T) {
+	deployment := func(worker *basev1alpha1.Worker) *appsv1.Deployment {
+		deploy, err := Deployment(worker, testModel, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return deploy
+	}
+	base := deployment(testWorker(nil))
+	if volume := base.Spec.Template.Spec.Volumes[0]; volume.EmptyDir == nil {
+		t.Errorf("expected an ephemeral volume for a worker without storage, got %+v", volume)
+	}
+	if _, err := Deployment(testWorker(func(w *basev1alpha1.Worker) { w.Spec.Runner.Image = "" }), testModel, nil); err == nil {
+		t.Error("expected an error for a worker without runner image")
+	}
+
+	tests := []struct {
+		name        string
+		mutate      func(*basev1alpha1.Worker)
+		wantChanged bool
+	}{
+		{name: "replicas are not part of the template", mutate: func(w *basev1alpha1.Worker) { w.Spec.Replicas = lo.ToPtr[int32](3) }},
+		{name: "runner image", mutate: func(w *basev1alpha1.Worker) { w.Spec.Runner.Image = "runner:v2" }, wantChanged: true},
+		{
+			name:        "storage",
+			mutate:      func(w *basev1alpha1.Worker) { w.Spec.Storage = &corev1.PersistentVolumeClaimSpec{} },
+			wantChanged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deploy := deployment(testWorker(tt.mutate))
+			if changed := deploy.Annotations[AnnotationTemplateHash] != base.Annotations[AnnotationTemplateHash]; changed != tt.wantChanged {
+				t.Errorf("expected the template hash to change %v, got %v", tt.wantChanged, changed)
+			}
+		})
+	}
+}
+
+func TestLoader(t *testing.T) {
+	withLoader := func(w *basev1alpha1.Worker) { w.Spec.Loader.Image = "loader:v1" }
+	huggingFace := &basev1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "qwen2"},
+		Spec:       basev1alpha1.ModelSpec{HuggingFaceRepo: "Qwen/Qwen2-7B-Instruct"},
+	}
+	fromDataSource := &basev1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "qwen2"},
+		Spec:       basev1alpha1.ModelSpec{Source: &corev1.TypedObjectReference{Name: "minio"}},
+	}
+	oss := &basev1alpha1.DataSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "minio"},
+		Spec: basev1alpha1.DataSourceSpec{
+			Endpoint: basev1alpha1.Endpoint{URL: "https://minio.example.com", AuthSecret: &corev1.TypedLocalObjectReference{Name: "minio-auth"}},
+			OSS:      &basev1alpha1.OSS{Bucket: "models", Object: "qwen2"},
+		},
+	}
+	tests := []struct {
+		name     string
+		worker   *basev1alpha1.Worker
+		model    *basev1alpha1.Model
+		ds       *basev1alpha1.DataSource
+		wantEnv  map[string]string
+		wantAuth string
+		wantErr  bool
+	}{
+		{name: "no source", worker: testWorker(nil), model: testModel},
+		{
+			name:    "huggingface",
+			worker:  testWorker(withLoader),
+			model:   huggingFace,
+			wantEnv: map[string]string{EnvLoaderSource: LoaderSourceHuggingFace, EnvLoaderRepo: "Qwen/Qwen2-7B-Instruct", EnvModelPath: "/data/models/qwen2"},
+		},
+		{
+			name:     "oss datasource",
+			worker:   testWorker(withLoader),
+			model:    fromDataSource,
+			ds:       oss,
+			wantEnv:  map[string]string{EnvLoaderSource: LoaderSourceOSS, EnvLoaderBucket: "models", EnvLoaderEndpoint: "https://minio.example.com"},
+			wantAuth: "minio-auth",
+		},
+		{name: "loader image required", worker: testWorker(nil), model: huggingFace, wantErr: true},
+		{name: "datasource not found", worker: testWorker(withLoader), model: fromDataSource, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader, err := Loader(tt.worker, tt.model, tt.ds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if (loader != nil) != (tt.wantEnv != nil) {
+				t.Fatalf("expected loader %v, got %+v", tt.wantEnv != nil, loader)
+			}
+			if loader == nil {
+				return
+			}
+			for name, want := range tt.wantEnv {
+				env, _ := lo.Find(loader.Env, func(env corev1.EnvVar) bool { return env.Name == name })
+				if env.Value != want {
+					t.Errorf("expected env %s %q, got %q", name, want, env.Value)
+				}
+			}
+			auth := ""
+			if len(loader.EnvFrom) > 0 {
+				auth = loader.EnvFrom[0].SecretRef.Name
+			}
+			if auth != tt.wantAuth {
+				t.Errorf("expected auth secret %q, got %q", tt.wantAuth, auth)
+			}
+		})
+	}
+}
+
+func TestVLLMRunner(t *testing.T) {
+	tests := []struct {
+		name     string
+		worker   *basev1alpha1.Worker
+		model    *basev1alpha1.Model
+		wantArgs []string
+	}{
+		{
+			name:     "defaults",
+			worker:   testWorker(nil),
+			model:    testModel,
+			wantArgs: []string{"--model", "/data/models/qwen2", "--served-model-name", "qwen2", "--host", "0.0.0.0", "--port", "21002"},
+		},
+		{
+			name: "gpus and context length",
+			worker: testWorker(func(w *basev1alpha1.Worker) {
+				w.Spec.Resources.Limits = corev1.ResourceList{ResourceGPU: resource.MustParse("2")}
+			}),
+			model: &basev1alpha1.Model{ObjectMeta: metav1.ObjectMeta{Name: "qwen2"}, Spec: basev1alpha1.ModelSpec{MaxContextLength: 8192}},
+			wantArgs: []string{"--model", "/data/models/qwen2", "--served-model-name", "qwen2", "--host", "0.0.0.0", "--port", "21002",
+				"--tensor-parallel-size", "2", "--max-model-len", "8192"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container, err := RunnerFor(basev1alpha1.WorkerTypeFastchatVLLM).Container(tt.worker, tt.model)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(container.Args, tt.wantArgs) {
+				t.Errorf("expected args %v, got %v", tt.wantArgs, container.Args)
+			}
+		})
+	}
+}
+
+// TestRunnerMetrics checks that the webhook only accepts autoscaling for the worker types whose runner reports its load
+func TestRunnerMetrics(t *testing.T) {
+	for _, workerType := range []basev1alpha1.WorkerType{basev1alpha1.WorkerTypeFastchatNormal, basev1alpha1.WorkerTypeFastchatVLLM, basev1alpha1.WorkerTypeKubeAGI} {
+		if got := RunnerFor(workerType).Metrics() != nil; got != workerType.ReportsLoad() {
+			t.Errorf("%s: expected the runner to report load %v, got %v", workerType, workerType.ReportsLoad(), got)
+		}
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		workerType basev1alpha1.WorkerType
+		want       string
+	}{
+		{workerType: basev1alpha1.WorkerTypeFastchatNormal, want: "http://qwen.default.svc:21002/v1"},
+		{workerType: basev1alpha1.WorkerTypeFastchatVLLM, want: "http://qwen.default.svc:21002/v1"},
+		{workerType: basev1alpha1.WorkerTypeKubeAGI},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.workerType), func(t *testing.T) {
+			endpoint := Endpoint(testWorker(func(w *basev1alpha1.Worker) { w.Spec.Type = tt.workerType }))
+			if got := lo.FromPtr(endpoint).URL; got != tt.want {
+				t.Errorf("expected endpoint %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLLM(t *testing.T) {
+	tests := []struct {
+		workerType basev1alpha1.WorkerType
+		wantLLM    bool
+	}{
+		{workerType: basev1alpha1.WorkerTypeFastchatVLLM, wantLLM: true},
+		// the llm could never become ready without an api to call
+		{workerType: basev1alpha1.WorkerTypeKubeAGI},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.workerType), func(t *testing.T) {
+			llm := LLM(testWorker(func(w *basev1alpha1.Worker) { w.Spec.Type = tt.workerType }), testModel)
+			if (llm != nil) != tt.wantLLM {
+				t.Fatalf("expected llm %v, got %+v", tt.wantLLM, llm)
+			}
+			if llm != nil && !reflect.DeepEqual(llm.Spec.Models, []string{"qwen2"}) {
+				t.Errorf("expected the served model, got %v", llm.Spec.Models)
+			}
+		})
+	}
+}